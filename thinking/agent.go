@@ -43,10 +43,15 @@ func runAgent(a Agent) {
 
 	fmt.Printf("🧠 [%s] Starting agent loop for %.0fs at %d APS\n", a.ID, lifespan.Seconds(), a.Config.Movement.Translation.ActionsPerSecond)
 
-	// Static dummy float64 input (shape: 1 row of 6 features)
-	dummyInput := [][]float64{{10.1, -10.2, 10.3, -10.4, 10.5, -10.6}}
+	center := []float64{a.PlanetPos.X * planetSpacing, a.PlanetPos.Y * planetSpacing, a.PlanetPos.Z * planetSpacing}
+	goal := []float64{center[0], center[1] + defaultGoalOffset, center[2]}
+	obs := newObserver(a.Config.Observation, lifespan)
+	inWidth, inHeight := 3, 1
+	if layers := a.Config.NetworkConfig.Layers; len(layers) > 0 {
+		inWidth, inHeight = layers[0].Width, layers[0].Height
+	}
 
-	position := Vec3{0, 0, 0}
+	position := Vec3{center[0], center[1], center[2]}
 	start := time.Now()
 	ticker := time.NewTicker(actionRate)
 	defer ticker.Stop()
@@ -58,6 +63,12 @@ func runAgent(a Agent) {
 		case <-ticker.C:
 			tickCount++
 
+			input, err := shapeInput(obs.Observe([]float64{position.X, position.Y, position.Z}, goal, center, time.Since(start)), inWidth, inHeight)
+			if err != nil {
+				fmt.Printf("⚠️ [%s] %v\n", a.ID, err)
+				return
+			}
+
 			switch net := a.Network.Net.(type) {
 			case *paragon.Network[float32], *paragon.Network[float64],
 				*paragon.Network[int], *paragon.Network[int8],
@@ -68,7 +79,7 @@ func runAgent(a Agent) {
 				// Use reflect to call the interface-typed network
 				// Forward pass
 				reflect.ValueOf(net).MethodByName("Forward").Call([]reflect.Value{
-					reflect.ValueOf(dummyInput),
+					reflect.ValueOf(input),
 				})
 
 				// Get output slice
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// defaultCheckpointSpacing is the band width used when checkpoint_spacing
+// is not set in the config.
+const defaultCheckpointSpacing = 10.0
+
+type CheckpointHit struct {
+	Time     float64   `json:"t"`        // seconds since pulsing started
+	Band     int       `json:"band"`     // distance band reached (0 = at the goal)
+	Distance float64   `json:"distance"` // distance to goal when the band was crossed
+	Position []float64 `json:"position"`
+}
+
+// checkpointTracker awards checkpoint_reward every time a cube moves into a
+// distance band closer to its goal than any band it has reached before.
+type checkpointTracker struct {
+	spacing float64
+	reward  float64
+	mu      sync.Mutex
+	best    map[string]int // closest band reached so far
+	hits    map[string][]CheckpointHit
+}
+
+func newCheckpointTracker(cfg *ExperimentConfig) *checkpointTracker {
+	spacing := cfg.CheckpointSpacing
+	if spacing <= 0 {
+		spacing = defaultCheckpointSpacing
+	}
+	return &checkpointTracker{
+		spacing: spacing,
+		reward:  float64(cfg.CheckpointReward),
+		best:    make(map[string]int),
+		hits:    make(map[string][]CheckpointHit),
+	}
+}
+
+func (t *checkpointTracker) band(dist float64) int {
+	return int(math.Floor(dist / t.spacing))
+}
+
+// Start records the band a cube spawned in; only closer bands pay out.
+func (t *checkpointTracker) Start(name string, start, goal []float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.best[name] = t.band(distance(start, goal))
+	t.hits[name] = []CheckpointHit{}
+}
+
+// Observe checks a sampled position and logs one hit per band crossed.
+func (t *checkpointTracker) Observe(name string, elapsed float64, pos, goal []float64) {
+	dist := distance(pos, goal)
+	band := t.band(dist)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	best, ok := t.best[name]
+	if !ok {
+		return
+	}
+	for b := best - 1; b >= band; b-- {
+		t.hits[name] = append(t.hits[name], CheckpointHit{
+			Time:     elapsed,
+			Band:     b,
+			Distance: dist,
+			Position: append([]float64{}, pos...),
+		})
+	}
+	if band < best {
+		t.best[name] = band
+	}
+}
+
+// Count is the number of bands a cube has crossed.
+func (t *checkpointTracker) Count(name string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.hits[name])
+}
+
+// Reward is the total checkpoint bonus earned by a cube.
+func (t *checkpointTracker) Reward(name string) float64 {
+	return float64(t.Count(name)) * t.reward
+}
+
+// Hits returns the per-cube hit lists for the variant summary.
+func (t *checkpointTracker) Hits() map[string][]CheckpointHit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string][]CheckpointHit, len(t.hits))
+	for name, h := range t.hits {
+		out[name] = h
+	}
+	return out
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// defaultFullCovarianceMax is the largest parameter count that gets a full
+// covariance matrix; bigger searches use the diagonal (sep-CMA) variant.
+const defaultFullCovarianceMax = 200
+
+// CMAState is the CMA-ES distribution for one type/mode at the start of a
+// generation. Cov is n×n row-major when full, length n when diagonal.
+// Candidate i is Mean + Sigma·B·D·z_i with z_i drawn from its seed and B, D
+// the eigen-decomposition of Cov, so candidates can always be rebuilt.
+type CMAState struct {
+	Generation int       `json:"generation"`
+	NumType    string    `json:"num_type"`
+	Mode       string    `json:"mode"`
+	Dim        int       `json:"dim"`
+	Diagonal   bool      `json:"diagonal"`
+	Sigma      float64   `json:"sigma"`
+	Mean       []float64 `json:"mean"`
+	Cov        []float64 `json:"cov"`
+	PathC      []float64 `json:"path_c"`
+	PathSigma  []float64 `json:"path_sigma"`
+	Updates    int       `json:"updates"` // generations folded into the paths so far
+}
+
+func cmaStatePath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "cmaes", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+func cmaMeanPath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "cmaes", fmt.Sprintf("%s_%s_mean.json", numType, mode))
+}
+
+func loadCMAState(gen int, numType, mode string) (*CMAState, error) {
+	data, err := os.ReadFile(cmaStatePath(gen, numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var s CMAState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func saveCMAState(s *CMAState) error {
+	path := cmaStatePath(s.Generation, s.NumType, s.Mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+func newCMAState(gen int, numType, mode string, mean []float64, sigma float64, fullMax int) *CMAState {
+	n := len(mean)
+	s := &CMAState{
+		Generation: gen, NumType: numType, Mode: mode, Dim: n,
+		Diagonal:  n > fullMax,
+		Sigma:     sigma,
+		Mean:      append([]float64{}, mean...),
+		PathC:     make([]float64, n),
+		PathSigma: make([]float64, n),
+	}
+	if s.Diagonal {
+		s.Cov = make([]float64, n)
+		for i := range s.Cov {
+			s.Cov[i] = 1
+		}
+	} else {
+		s.Cov = make([]float64, n*n)
+		for i := 0; i < n; i++ {
+			s.Cov[i*n+i] = 1
+		}
+	}
+	return s
+}
+
+// cmaBasis holds the eigen-decomposition Cov = B·diag(D²)·Bᵀ. For the
+// diagonal variant B is the identity and is left nil.
+type cmaBasis struct {
+	B []float64 // n×n row-major, columns are eigenvectors
+	D []float64 // square roots of the eigenvalues
+}
+
+func (s *CMAState) basis() cmaBasis {
+	n := s.Dim
+	if s.Diagonal {
+		d := make([]float64, n)
+		for i, c := range s.Cov {
+			d[i] = math.Sqrt(math.Max(c, 1e-20))
+		}
+		return cmaBasis{D: d}
+	}
+	vals, vecs := symmetricEigen(s.Cov, n)
+	d := make([]float64, n)
+	for i, v := range vals {
+		d[i] = math.Sqrt(math.Max(v, 1e-20))
+	}
+	return cmaBasis{B: vecs, D: d}
+}
+
+// y returns B·D·z, the candidate's step before scaling by sigma.
+func (b cmaBasis) y(z []float64) []float64 {
+	n := len(z)
+	out := make([]float64, n)
+	if b.B == nil {
+		for i := range z {
+			out[i] = b.D[i] * z[i]
+		}
+		return out
+	}
+	for i := 0; i < n; i++ {
+		sum := 0.0
+		for k := 0; k < n; k++ {
+			sum += b.B[i*n+k] * b.D[k] * z[k]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+// invSqrt returns Cov^(-1/2)·v = B·D⁻¹·Bᵀ·v.
+func (b cmaBasis) invSqrt(v []float64) []float64 {
+	n := len(v)
+	out := make([]float64, n)
+	if b.B == nil {
+		for i := range v {
+			out[i] = v[i] / b.D[i]
+		}
+		return out
+	}
+	tmp := make([]float64, n)
+	for k := 0; k < n; k++ {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += b.B[i*n+k] * v[i]
+		}
+		tmp[k] = sum / b.D[k]
+	}
+	for i := 0; i < n; i++ {
+		sum := 0.0
+		for k := 0; k < n; k++ {
+			sum += b.B[i*n+k] * tmp[k]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+func cmaNoise(cfg *ExperimentConfig, gen int, numType, mode string, variant, dim int) []float64 {
+	rng := rand.New(rand.NewSource(variantSeed(cfg.Seed, gen, numType, mode, variant)))
+	z := make([]float64, dim)
+	for j := range z {
+		z[j] = rng.NormFloat64()
+	}
+	return z
+}
+
+// cmaUpdate folds one evaluated generation into the distribution, following
+// Hansen's tutorial formulation with recombination weights recomputed over
+// the candidates that were actually scored. The diagonal variant uses the
+// sep-CMA learning rates.
+func cmaUpdate(prev *CMAState, ys [][]float64, scores []float64, basis cmaBasis) *CMAState {
+	n := prev.Dim
+	fn := float64(n)
+
+	// best first (fitness is maximised)
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	mu := max(1, len(order)/2)
+	weights := make([]float64, mu)
+	sum := 0.0
+	for i := range weights {
+		weights[i] = math.Log(float64(mu)+0.5) - math.Log(float64(i+1))
+		sum += weights[i]
+	}
+	sumSq := 0.0
+	for i := range weights {
+		weights[i] /= sum
+		sumSq += weights[i] * weights[i]
+	}
+	mueff := 1 / sumSq
+
+	cs := (mueff + 2) / (fn + mueff + 5)
+	ds := 1 + 2*math.Max(0, math.Sqrt((mueff-1)/(fn+1))-1) + cs
+	cc := (4 + mueff/fn) / (fn + 4 + 2*mueff/fn)
+	c1 := 2 / ((fn+1.3)*(fn+1.3) + mueff)
+	cmu := math.Min(1-c1, 2*(mueff-2+1/mueff)/((fn+2)*(fn+2)+mueff))
+	if prev.Diagonal {
+		c1 = math.Min(1, c1*(fn+2)/3)
+		cmu = math.Min(1-c1, cmu*(fn+2)/3)
+	}
+	chiN := math.Sqrt(fn) * (1 - 1/(4*fn) + 1/(21*fn*fn))
+
+	next := &CMAState{
+		Generation: prev.Generation + 1, NumType: prev.NumType, Mode: prev.Mode,
+		Dim: n, Diagonal: prev.Diagonal, Updates: prev.Updates + 1,
+		Mean:      make([]float64, n),
+		PathC:     make([]float64, n),
+		PathSigma: make([]float64, n),
+		Cov:       make([]float64, len(prev.Cov)),
+	}
+
+	yw := make([]float64, n)
+	for k := 0; k < mu; k++ {
+		y := ys[order[k]]
+		for j := range yw {
+			yw[j] += weights[k] * y[j]
+		}
+	}
+	for j := range yw {
+		next.Mean[j] = prev.Mean[j] + prev.Sigma*yw[j]
+	}
+
+	invY := basis.invSqrt(yw)
+	psNorm := 0.0
+	for j := range invY {
+		next.PathSigma[j] = (1-cs)*prev.PathSigma[j] + math.Sqrt(cs*(2-cs)*mueff)*invY[j]
+		psNorm += next.PathSigma[j] * next.PathSigma[j]
+	}
+	psNorm = math.Sqrt(psNorm)
+
+	hsig := 0.0
+	if psNorm/math.Sqrt(1-math.Pow(1-cs, 2*float64(next.Updates)))/chiN < 1.4+2/(fn+1) {
+		hsig = 1
+	}
+	for j := range yw {
+		next.PathC[j] = (1-cc)*prev.PathC[j] + hsig*math.Sqrt(cc*(2-cc)*mueff)*yw[j]
+	}
+
+	decay := 1 - c1 - cmu + (1-hsig)*c1*cc*(2-cc)
+	if prev.Diagonal {
+		for j := 0; j < n; j++ {
+			rankMu := 0.0
+			for k := 0; k < mu; k++ {
+				y := ys[order[k]]
+				rankMu += weights[k] * y[j] * y[j]
+			}
+			next.Cov[j] = decay*prev.Cov[j] + c1*next.PathC[j]*next.PathC[j] + cmu*rankMu
+		}
+	} else {
+		for a := 0; a < n; a++ {
+			for b := 0; b <= a; b++ {
+				rankMu := 0.0
+				for k := 0; k < mu; k++ {
+					y := ys[order[k]]
+					rankMu += weights[k] * y[a] * y[b]
+				}
+				v := decay*prev.Cov[a*n+b] + c1*next.PathC[a]*next.PathC[b] + cmu*rankMu
+				next.Cov[a*n+b], next.Cov[b*n+a] = v, v
+			}
+		}
+	}
+
+	next.Sigma = prev.Sigma * math.Exp((cs/ds)*(psNorm/chiN-1))
+	return next
+}
+
+// cmaState loads this generation's distribution, or builds it from the base
+// model (generation 0) or from the previous generation's scored candidates.
+func (e *Experiment[T, M]) cmaState(template *paragon.Network[T], refs []paramRef) (*CMAState, error) {
+	mode := e.Mode.String()
+	if s, err := loadCMAState(e.Gen, e.NumType, mode); err == nil {
+		if s.Dim != len(refs) {
+			return nil, fmt.Errorf("cmaes state has %d parameters, network has %d", s.Dim, len(refs))
+		}
+		return s, nil
+	}
+
+	if e.Gen == 0 {
+		sigma := e.Config.SpectrumMaxStdDev
+		if _, _, integer := numericRange[T](); integer {
+			sigma *= integerNoiseScale
+		}
+		fullMax := e.Config.CMAES.FullCovarianceMax
+		if fullMax <= 0 {
+			fullMax = defaultFullCovarianceMax
+		}
+		return newCMAState(0, e.NumType, mode, getParams(template, refs), sigma, fullMax), nil
+	}
+
+	prev, err := loadCMAState(e.Gen-1, e.NumType, mode)
+	if err != nil {
+		return nil, fmt.Errorf("previous cmaes state: %w", err)
+	}
+	if prev.Dim != len(refs) {
+		return nil, fmt.Errorf("cmaes state has %d parameters, network has %d", prev.Dim, len(refs))
+	}
+	variants, scores, err := evaluatedVariants(e.Gen-1, e.NumType, mode, e.Config.VariantCount())
+	if err != nil {
+		return nil, err
+	}
+
+	basis := prev.basis()
+	ys := make([][]float64, len(variants))
+	for k, v := range variants {
+		ys[k] = basis.y(cmaNoise(e.Config, e.Gen-1, e.NumType, mode, v, prev.Dim))
+	}
+	return cmaUpdate(prev, ys, scores, basis), nil
+}
+
+// generateCMAES samples this generation's candidates from the CMA-ES
+// distribution. A restart reloads models/<gen>/cmaes/ and resumes.
+func (e *Experiment[T, M]) generateCMAES(ctx context.Context) {
+	mode := e.Mode.String()
+	template, refs, err := e.searchTemplate()
+	if err != nil {
+		fmt.Printf("❌ CMA-ES setup failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+
+	state, err := e.cmaState(template, refs)
+	if err != nil {
+		fmt.Printf("❌ CMA-ES update failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	if err := saveCMAState(state); err != nil {
+		fmt.Printf("❌ Failed to save CMA-ES state: %v\n", err)
+		return
+	}
+	e.reportSigma(state.Sigma, OptimizerCMAES)
+
+	basis := state.basis()
+	vals := make([]float64, state.Dim)
+	err = e.writeSamples(ctx, template, refs, state.Mean, cmaMeanPath(e.Gen, e.NumType, mode), func(i int) ([]float64, VariantRecord) {
+		y := basis.y(cmaNoise(e.Config, e.Gen, e.NumType, mode, i, state.Dim))
+		for j := range vals {
+			vals[j] = state.Mean[j] + state.Sigma*y[j]
+		}
+		return vals, VariantRecord{
+			Source: "cmaes",
+			Seed:   variantSeed(e.Config.Seed, e.Gen, e.NumType, mode, i),
+			StdDev: state.Sigma,
+		}
+	})
+	if err != nil {
+		fmt.Printf("❌ CMA-ES sampling failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+
+	kind := "full"
+	if state.Diagonal {
+		kind = "diagonal"
+	}
+	fmt.Printf("💾 CMA-ES generation %d for %s_%s: %d params, %s covariance, σ=%.4f\n",
+		e.Gen, e.NumType, mode, state.Dim, kind, state.Sigma)
+}
+
+// symmetricEigen diagonalises a symmetric n×n matrix with cyclic Jacobi
+// rotations. It returns the eigenvalues and the eigenvectors as columns of
+// a row-major matrix. Fine for the few hundred parameters full CMA-ES is
+// used for.
+func symmetricEigen(m []float64, n int) ([]float64, []float64) {
+	a := append([]float64{}, m...)
+	v := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		v[i*n+i] = 1
+	}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += a[p*n+q] * a[p*n+q]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p*n+q]
+				if math.Abs(apq) < 1e-300 {
+					continue
+				}
+				theta := (a[q*n+q] - a[p*n+p]) / (2 * apq)
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k*n+p], a[k*n+q]
+					a[k*n+p] = c*akp - s*akq
+					a[k*n+q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p*n+k], a[q*n+k]
+					a[p*n+k] = c*apk - s*aqk
+					a[q*n+k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k*n+p], v[k*n+q]
+					v[k*n+p] = c*vkp - s*vkq
+					v[k*n+q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = a[i*n+i]
+	}
+	return vals, v
+}
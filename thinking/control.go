@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Control actions accepted on the experiment_control WebSocket message
+const (
+	ControlStart  = "start"
+	ControlPause  = "pause"
+	ControlResume = "resume"
+	ControlStop   = "stop" // finish the current variant, then exit the loop
+	ControlAbort  = "abort"
+)
+
+// Run states reported back in every control acknowledgement
+const (
+	RunIdle     = "idle"
+	RunRunning  = "running"
+	RunPaused   = "paused"
+	RunStopping = "stopping"
+	RunAborting = "aborting"
+)
+
+// ExperimentController owns the lifecycle of RunEpisodeLoop so it can be
+// started and steered from the dashboard instead of only from auto_state.
+type ExperimentController struct {
+	mu     sync.Mutex
+	state  string
+	resume chan struct{} // closed to release a paused loop
+	base   context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the running loop has exited
+}
+
+var controller = &ExperimentController{state: RunIdle, base: context.Background()}
+
+type ControlAck struct {
+	Action string `json:"action"`
+	OK     bool   `json:"ok"`
+	State  string `json:"state"`
+	Error  string `json:"error,omitempty"`
+}
+
+// State returns the current run state.
+func (c *ExperimentController) State() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// SetContext sets the parent context every run is derived from, so process
+// shutdown cancels whatever loop is in flight.
+func (c *ExperimentController) SetContext(ctx context.Context) {
+	c.mu.Lock()
+	c.base = ctx
+	c.mu.Unlock()
+}
+
+// Start launches RunEpisodeLoop in the background if nothing is running.
+func (c *ExperimentController) Start(cfg *ExperimentConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("no experiment config loaded")
+	}
+
+	c.mu.Lock()
+	if c.state != RunIdle {
+		state := c.state
+		c.mu.Unlock()
+		return fmt.Errorf("experiment already %s", state)
+	}
+	if c.base.Err() != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("shutting down")
+	}
+	ctx, cancel := context.WithCancel(c.base)
+	done := make(chan struct{})
+	c.state = RunRunning
+	c.cancel = cancel
+	c.done = done
+	c.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		RunEpisodeLoop(ctx, cfg)
+		cancel()
+		c.finish()
+	}()
+	return nil
+}
+
+// Done returns a channel closed when the current run exits, or nil if idle.
+func (c *ExperimentController) Done() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == RunIdle {
+		return nil
+	}
+	return c.done
+}
+
+// Pause holds the loop at the next variant boundary.
+func (c *ExperimentController) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != RunRunning {
+		return fmt.Errorf("cannot pause while %s", c.state)
+	}
+	c.state = RunPaused
+	c.resume = make(chan struct{})
+	return nil
+}
+
+// Resume releases a paused loop.
+func (c *ExperimentController) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != RunPaused {
+		return fmt.Errorf("cannot resume while %s", c.state)
+	}
+	c.state = RunRunning
+	close(c.resume)
+	c.resume = nil
+	return nil
+}
+
+// StopAfterVariant lets the current variant finish and then ends the loop.
+func (c *ExperimentController) StopAfterVariant() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case RunRunning:
+	case RunPaused:
+		close(c.resume)
+		c.resume = nil
+	default:
+		return fmt.Errorf("cannot stop while %s", c.state)
+	}
+	c.state = RunStopping
+	return nil
+}
+
+// Abort cancels the running loop; the current variant's cubes are despawned.
+func (c *ExperimentController) Abort() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case RunRunning, RunStopping:
+	case RunPaused:
+		close(c.resume)
+		c.resume = nil
+	default:
+		return fmt.Errorf("cannot abort while %s", c.state)
+	}
+	c.state = RunAborting
+	c.cancel()
+	return nil
+}
+
+// AtVariantBoundary blocks while paused and reports whether the loop may
+// start another variant.
+func (c *ExperimentController) AtVariantBoundary(ctx context.Context) bool {
+	c.mu.Lock()
+	resume := c.resume
+	c.mu.Unlock()
+
+	if resume != nil {
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return ctx.Err() == nil && c.State() == RunRunning
+}
+
+func (c *ExperimentController) finish() {
+	c.mu.Lock()
+	prev := c.state
+	c.state = RunIdle
+	c.resume = nil
+	c.cancel = nil
+	c.mu.Unlock()
+
+	AppendStatus(latestGeneration(), "", "", -1, "Control", fmt.Sprintf("Episode loop exited (%s)", prev))
+}
+
+// HandleControl applies a dashboard control action and builds its reply.
+func (c *ExperimentController) HandleControl(action string) ControlAck {
+	var err error
+	switch action {
+	case ControlStart:
+		err = c.Start(experimentConfig)
+	case ControlPause:
+		err = c.Pause()
+	case ControlResume:
+		err = c.Resume()
+	case ControlStop:
+		err = c.StopAfterVariant()
+	case ControlAbort:
+		err = c.Abort()
+	default:
+		err = fmt.Errorf("unknown action %q", action)
+	}
+
+	ack := ControlAck{Action: action, OK: err == nil, State: c.State()}
+	if err != nil {
+		ack.Error = err.Error()
+		AppendStatus(latestGeneration(), "", "", -1, "Control", fmt.Sprintf("%s rejected: %v", action, err))
+	} else {
+		AppendStatus(latestGeneration(), "", "", -1, "Control", fmt.Sprintf("%s accepted — now %s", action, ack.State))
+	}
+	return ack
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// defaultCrossoverRate is the share of children bred by crossover when
+// crossover is enabled without a rate.
+const defaultCrossoverRate = 0.5
+
+// Crossover operators selectable with crossover.operator
+const (
+	CrossoverUniform = "uniform" // each weight and bias from either parent
+	CrossoverNeuron  = "neuron"  // whole neurons (bias + incoming weights)
+	CrossoverLayer   = "layer"   // whole layers
+)
+
+func (c CrossoverConfig) OperatorName() string {
+	if c.Operator == "" {
+		return CrossoverUniform
+	}
+	return strings.ToLower(c.Operator)
+}
+
+func (c CrossoverConfig) RateOrDefault() float64 {
+	if c.Rate <= 0 || c.Rate > 1 {
+		return defaultCrossoverRate
+	}
+	return c.Rate
+}
+
+// Children is how many of n spectrum slots are filled by crossover.
+func (c CrossoverConfig) Children(n int) int {
+	if !c.Enabled {
+		return 0
+	}
+	return int(math.Round(c.RateOrDefault() * float64(n)))
+}
+
+// crossoverNetworks builds a child of two parents with the same
+// architecture. The operator decides the unit inherited as a whole; each
+// unit comes from a or b with equal probability.
+func crossoverNetworks[T paragon.Numeric](a, b *paragon.Network[T], operator string, rng *rand.Rand) (*paragon.Network[T], error) {
+	refsA, refsB := paramLayout(a), paramLayout(b)
+	if len(refsA) != len(refsB) {
+		return nil, fmt.Errorf("parents differ in size (%d vs %d parameters)", len(refsA), len(refsB))
+	}
+	for i := range refsA {
+		if refsA[i] != refsB[i] {
+			return nil, fmt.Errorf("parents differ in architecture at %+v", refsA[i])
+		}
+	}
+
+	var unit func(r paramRef) paramRef
+	switch operator {
+	case CrossoverUniform:
+		unit = func(r paramRef) paramRef { return r }
+	case CrossoverNeuron:
+		unit = func(r paramRef) paramRef { return paramRef{r.Layer, r.Y, r.X, 0} }
+	case CrossoverLayer:
+		unit = func(r paramRef) paramRef { return paramRef{Layer: r.Layer} }
+	default:
+		return nil, fmt.Errorf("unknown crossover operator %q", operator)
+	}
+
+	child, err := cloneNetwork(a)
+	if err != nil {
+		return nil, err
+	}
+	valsA, valsB := getParams(a, refsA), getParams(b, refsA)
+	fromB := map[paramRef]bool{}
+	for i, r := range refsA {
+		u := unit(r)
+		pick, ok := fromB[u]
+		if !ok {
+			pick = rng.Intn(2) == 1
+			fromB[u] = pick
+		}
+		if pick {
+			valsA[i] = valsB[i]
+		}
+	}
+	setParams(child, refsA, valsA)
+	return child, nil
+}
+
+// deriveCrossover breeds a child and, when mutate is set, perturbs it with
+// the mutation strategy. Like deriveVariant it is fully determined by its
+// inputs and seed.
+func deriveCrossover[T paragon.Numeric](a, b *paragon.Network[T], cx CrossoverConfig, ms MutationStrategy, stddev float64, seed int64) (*paragon.Network[T], error) {
+	rng := rand.New(rand.NewSource(seed))
+	child, err := crossoverNetworks(a, b, cx.OperatorName(), rng)
+	if err != nil {
+		return nil, err
+	}
+	if cx.MutateChildren {
+		if err := mutateNetwork(child, ms, stddev, rng); err != nil {
+			return nil, err
+		}
+	}
+	return child, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// legacyClamp is what construct.Cube used when no movement clamp is set
+const legacyClamp = 20.0
+
+// AgentCube drives one cube through a SimBackend. Unlike construct.Cube it
+// clamps every axis separately and can drive rotation from extra output
+// channels. Network outputs are read as [fx, fy, fz, tx, ty, tz]; torque is
+// only sent when the output layer is at least 6 wide.
+type AgentCube[T paragon.Numeric] struct {
+	Name        string
+	UnitName    string
+	Position    []float64
+	Model       *paragon.Network[T]
+	Sim         SimBackend
+	ForceClamp  Vector3
+	TorqueClamp Vector3
+	LastOutput  []float64 // raw network output from the most recent pulse
+	Energy      float64   // sum of |force| and |torque| components sent so far
+
+	// where the cube was spawned and what it is scored against
+	Planet       string
+	PlanetCenter []float64
+	SpawnPos     []float64
+	Goal         []float64
+
+	Observer *observer // builds the network input; nil feeds the raw position
+}
+
+// Spawn creates the cube in the world under the name the backend gives it.
+func (c *AgentCube[T]) Spawn() error {
+	name, err := c.Sim.SpawnCube(c.Name, c.Position)
+	if err != nil {
+		return fmt.Errorf("❌ [%s] spawn failed: %w", c.Name, err)
+	}
+	c.Name = name
+	return nil
+}
+
+// Despawn removes the cube from the world.
+func (c *AgentCube[T]) Despawn() error {
+	if err := c.Sim.DespawnCube(c.Name); err != nil {
+		return fmt.Errorf("❌ [%s] despawn failed: %w", c.Name, err)
+	}
+	return nil
+}
+
+// Pulse runs the model on the cube's observation elapsed into its life,
+// applies the clamped force (and torque when withTorque is set), then
+// refreshes the position.
+func (c *AgentCube[T]) Pulse(withTorque bool, elapsed time.Duration) error {
+	obs := []float64{c.Position[0], c.Position[1], c.Position[2]}
+	if c.Observer != nil {
+		obs = c.Observer.Observe(c.Position, c.Goal, c.PlanetCenter, elapsed)
+	}
+	in := c.Model.Layers[0]
+	input, err := shapeInput(obs, in.Width, in.Height)
+	if err != nil {
+		return fmt.Errorf("❌ [%s] %w", c.Name, err)
+	}
+	c.Model.Forward(input)
+	output := c.Model.GetOutput()
+	c.LastOutput = output
+
+	if len(output) < 3 {
+		return fmt.Errorf("❌ [%s] model output too short", c.Name)
+	}
+
+	force := clampAxes(output[0:3], c.ForceClamp)
+	c.Energy += absSum(force)
+	if err := c.Sim.ApplyForce(c.Name, force); err != nil {
+		return fmt.Errorf("❌ [%s] apply_force failed: %w", c.Name, err)
+	}
+
+	if withTorque && len(output) >= 6 {
+		torque := clampAxes(output[3:6], c.TorqueClamp)
+		c.Energy += absSum(torque)
+		if err := c.Sim.ApplyTorque(c.Name, torque); err != nil {
+			return fmt.Errorf("❌ [%s] apply_torque failed: %w", c.Name, err)
+		}
+	}
+
+	return c.RefreshPosition()
+}
+
+// RefreshPosition asks the world for the cube's current position.
+func (c *AgentCube[T]) RefreshPosition() error {
+	pos, err := c.Sim.Position(c.Name)
+	if err != nil {
+		return fmt.Errorf("❌ [%s] %w", c.Name, err)
+	}
+	copy(c.Position, pos)
+	return nil
+}
+
+// clampAxes clamps each component to ±limit on its own axis.
+func clampAxes(v []float64, limit Vector3) []float64 {
+	return []float64{
+		clamp(v[0], limit.X),
+		clamp(v[1], limit.Y),
+		clamp(v[2], limit.Z),
+	}
+}
+
+func absSum(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += math.Abs(x)
+	}
+	return sum
+}
+
+// movementClamp treats an all-zero clamp as unset and falls back to the
+// limit construct.Cube used, so configs without a movement block still move.
+func movementClamp(v Vector3) Vector3 {
+	if v.X == 0 && v.Y == 0 && v.Z == 0 {
+		return Vector3{legacyClamp, legacyClamp, legacyClamp}
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package main
+
+import "fmt"
+
+// Evaluation geometry used when no curriculum stage overrides it.
+const (
+	planetSpacing      = 800.0 // world units between planet grid coordinates
+	defaultSpawnRadius = 120.0
+	defaultGoalOffset  = 100.0 // goal height above the planet center
+)
+
+// PlanetList is the stage's share of the configured planets, in order.
+func (s CurriculumStage) PlanetList(all []string) []string {
+	if s.Planets <= 0 || s.Planets >= len(all) {
+		return all
+	}
+	return all[:s.Planets]
+}
+
+func (s CurriculumStage) Radius() float64 {
+	if s.SpawnRadius <= 0 {
+		return defaultSpawnRadius
+	}
+	return s.SpawnRadius
+}
+
+func (s CurriculumStage) Offset() float64 {
+	if s.GoalOffset <= 0 {
+		return defaultGoalOffset
+	}
+	return s.GoalOffset
+}
+
+// curriculumStage is the stage a type/mode evaluates at in gen. Generation 0
+// starts at stage 0; each later generation keeps its predecessor's stage,
+// moving on once that generation's best mean_progress reached the stage's
+// promote_at. The result is stored in the generation manifest, so reruns
+// and the champion comparison see the same stage.
+func curriculumStage(cfg *ExperimentConfig, gen int, numType, mode string) (int, error) {
+	stages := cfg.Curriculum.Stages
+	if m, err := LoadManifest(gen); err == nil {
+		if stage, ok := m.Curriculum[numType+"_"+mode]; ok {
+			return min(stage, len(stages)-1), nil
+		}
+	}
+
+	stage := 0
+	if gen > 0 {
+		prev, _ := recordedStage(gen-1, numType, mode)
+		stage = min(prev, len(stages)-1)
+		if ranked, err := loadRankedResults(gen-1, numType, mode); err == nil && len(ranked) > 0 {
+			if stage < len(stages)-1 && fittestResult(ranked).MeanProgress >= stages[stage].PromoteAt {
+				stage++
+			}
+		}
+	}
+	return stage, RecordCurriculumStage(gen, numType, mode, stage)
+}
+
+// recordedStage reads the stage a generation ran at from its manifest.
+func recordedStage(gen int, numType, mode string) (int, bool) {
+	m, err := LoadManifest(gen)
+	if err != nil {
+		return 0, false
+	}
+	stage, ok := m.Curriculum[numType+"_"+mode]
+	return stage, ok
+}
+
+// evaluationStage is the geometry this generation's variants are scored
+// on: the current curriculum stage, or every planet at the default radius
+// and goal offset when the curriculum is off.
+func (e *Experiment[T, M]) evaluationStage() (int, CurriculumStage) {
+	if !e.Config.Curriculum.Enabled || len(e.Config.Curriculum.Stages) == 0 {
+		return -1, CurriculumStage{}
+	}
+	stage, err := curriculumStage(e.Config, e.Gen, e.NumType, e.Mode.String())
+	if err != nil {
+		fmt.Printf("⚠️ Could not record curriculum stage: %v\n", err)
+	}
+	return stage, e.Config.Curriculum.Stages[stage]
+}
+
+// reportCurriculum announces the generation's stage once, when variants
+// are generated.
+func (e *Experiment[T, M]) reportCurriculum() {
+	index, stage := e.evaluationStage()
+	if index < 0 {
+		return
+	}
+	planets := stage.PlanetList(e.Config.TrainingPlanets())
+	msg := fmt.Sprintf("Stage %d/%d: %d planet(s), spawn radius %.0f, goal offset %.0f",
+		index+1, len(e.Config.Curriculum.Stages), len(planets), stage.Radius(), stage.Offset())
+	AppendCurriculumStatus(e.Gen, e.NumType, e.Mode.String(), index, msg)
+	fmt.Printf("🎓 %s_%s %s\n", e.NumType, e.Mode.String(), msg)
+}
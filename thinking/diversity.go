@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Selection modes selectable with diversity.mode. Fitness stays the
+// champion's yardstick in every mode; the mode only changes which variants
+// become parents.
+const (
+	DiversityFitness   = "fitness"
+	DiversityNovelty   = "novelty"
+	DiversityMapElites = "map_elites"
+)
+
+// Behavior descriptors selectable with diversity.descriptor
+const (
+	DescriptorDisplacement       = "displacement"        // mean final−spawn offset (x, y, z)
+	DescriptorPlanetDisplacement = "planet_displacement" // the same per planet, planets in name order
+	DescriptorReach              = "reach"               // mean horizontal (xz) distance and mean Δy
+)
+
+const (
+	defaultNoveltyK       = 10
+	defaultArchiveAdd     = 1
+	defaultMapElitesBins  = 10
+	defaultMapElitesRange = 50.0
+)
+
+func (d DiversityConfig) ModeName() string {
+	if d.Mode == "" {
+		return DiversityFitness
+	}
+	return strings.ToLower(d.Mode)
+}
+
+func (d DiversityConfig) DescriptorName() string {
+	if d.Descriptor == "" {
+		return DescriptorDisplacement
+	}
+	return strings.ToLower(d.Descriptor)
+}
+
+// behaviorSample is what a descriptor sees of one evaluated cube.
+type behaviorSample struct {
+	Planet     string
+	Start, End []float64
+}
+
+// behaviorDescriptor summarises how a variant's cubes moved.
+func behaviorDescriptor(kind string, samples []behaviorSample) []float64 {
+	meanOffset := func(ss []behaviorSample) []float64 {
+		out := make([]float64, 3)
+		for _, s := range ss {
+			for k := 0; k < 3; k++ {
+				out[k] += s.End[k] - s.Start[k]
+			}
+		}
+		for k := range out {
+			if len(ss) > 0 {
+				out[k] /= float64(len(ss))
+			}
+		}
+		return out
+	}
+
+	switch kind {
+	case DescriptorPlanetDisplacement:
+		byPlanet := map[string][]behaviorSample{}
+		for _, s := range samples {
+			byPlanet[s.Planet] = append(byPlanet[s.Planet], s)
+		}
+		names := make([]string, 0, len(byPlanet))
+		for name := range byPlanet {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var out []float64
+		for _, name := range names {
+			out = append(out, meanOffset(byPlanet[name])...)
+		}
+		return out
+	case DescriptorReach:
+		var horizontal, vertical float64
+		for _, s := range samples {
+			dx, dz := s.End[0]-s.Start[0], s.End[2]-s.Start[2]
+			horizontal += math.Hypot(dx, dz)
+			vertical += s.End[1] - s.Start[1]
+		}
+		if n := float64(len(samples)); n > 0 {
+			horizontal, vertical = horizontal/n, vertical/n
+		}
+		return []float64{horizontal, vertical}
+	default:
+		return meanOffset(samples)
+	}
+}
+
+// descriptorDistance treats missing trailing dimensions as zero, so
+// per-planet descriptors stay comparable if a planet had no cubes.
+func descriptorDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y float64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		sum += (x - y) * (x - y)
+	}
+	return math.Sqrt(sum)
+}
+
+// ArchiveEntry is one remembered variant.
+type ArchiveEntry struct {
+	Generation int       `json:"generation"`
+	Variant    int       `json:"variant"`
+	Path       string    `json:"path"`
+	Descriptor []float64 `json:"descriptor"`
+	Fitness    float64   `json:"fitness"`
+	Novelty    float64   `json:"novelty,omitempty"`
+	Cell       string    `json:"cell,omitempty"` // map_elites grid cell, "i,j,…"
+
+	// Displaced is the elite from an earlier generation this entry took
+	// the cell from, so an aggregation rerun can put it back.
+	Displaced *ArchiveEntry `json:"displaced,omitempty"`
+}
+
+// BehaviorArchive persists across generations at
+// models/archive/<type>_<mode>.json. In novelty mode it is the list of
+// novel behaviours seen so far; in map_elites mode it holds the best
+// variant per grid cell.
+type BehaviorArchive struct {
+	NumType    string         `json:"num_type"`
+	Mode       string         `json:"mode"`
+	Kind       string         `json:"kind"` // novelty or map_elites
+	Descriptor string         `json:"descriptor"`
+	Entries    []ArchiveEntry `json:"entries"`
+}
+
+var archiveMu sync.Mutex
+
+func archivePath(numType, mode string) string {
+	return filepath.Join("models", "archive", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+func LoadArchive(numType, mode string) (*BehaviorArchive, error) {
+	data, err := os.ReadFile(archivePath(numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var a BehaviorArchive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func saveArchive(a *BehaviorArchive) error {
+	path := archivePath(a.NumType, a.Mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// LoadAllArchives returns every archive on disk, for the API and dashboard.
+func LoadAllArchives() []*BehaviorArchive {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join("models", "archive"))
+	if err != nil {
+		return nil
+	}
+	var out []*BehaviorArchive
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(entry.Name(), ".json"), "_", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if a, err := LoadArchive(parts[0], parts[1]); err == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// mapElitesCell bins the descriptor into the grid; dimensions beyond
+// diversity.ranges use ±50.
+func mapElitesCell(d DiversityConfig, desc []float64) string {
+	bins := d.Bins
+	if bins <= 0 {
+		bins = defaultMapElitesBins
+	}
+	parts := make([]string, len(desc))
+	for i, v := range desc {
+		lo, hi := -defaultMapElitesRange, defaultMapElitesRange
+		if i < len(d.Ranges) && d.Ranges[i][1] > d.Ranges[i][0] {
+			lo, hi = d.Ranges[i][0], d.Ranges[i][1]
+		}
+		b := int(math.Floor((v - lo) / (hi - lo) * float64(bins)))
+		parts[i] = strconv.Itoa(min(max(b, 0), bins-1))
+	}
+	return strings.Join(parts, ",")
+}
+
+// applyDiversity updates the archive with a generation's evaluated variants.
+// In novelty mode it also sets each result's selection score to its
+// novelty; map_elites leaves selection alone, since its parents come from
+// the grid. descriptors is keyed by variant id as written in total_results.
+func applyDiversity(cfg *ExperimentConfig, gen int, numType, mode string, results []rankedResult, descriptors map[string][]float64) error {
+	d := cfg.Diversity
+	kind := d.ModeName()
+	if kind == DiversityFitness {
+		return nil
+	}
+
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := LoadArchive(numType, mode)
+	if err != nil {
+		archive = &BehaviorArchive{NumType: numType, Mode: mode, Kind: kind, Descriptor: d.DescriptorName()}
+	}
+
+	// an aggregation rerun after a crash must not add the generation twice:
+	// drop its entries and restore the elites they displaced
+	kept := archive.Entries[:0]
+	for _, entry := range archive.Entries {
+		switch {
+		case entry.Generation != gen:
+			kept = append(kept, entry)
+		case entry.Displaced != nil:
+			kept = append(kept, *entry.Displaced)
+		}
+	}
+	archive.Entries = kept
+
+	var current []ArchiveEntry
+	for i := range results {
+		v, err := strconv.Atoi(results[i].Variant)
+		desc, ok := descriptors[results[i].Variant]
+		if err != nil || !ok {
+			continue
+		}
+		current = append(current, ArchiveEntry{
+			Generation: gen,
+			Variant:    v,
+			Path:       variantPath(gen, numType, mode, v),
+			Descriptor: desc,
+			Fitness:    results[i].MeanProgress,
+		})
+	}
+
+	switch kind {
+	case DiversityNovelty:
+		k := d.K
+		if k <= 0 {
+			k = defaultNoveltyK
+		}
+		for i := range current {
+			var dists []float64
+			for _, entry := range archive.Entries {
+				dists = append(dists, descriptorDistance(current[i].Descriptor, entry.Descriptor))
+			}
+			for j := range current {
+				if j != i {
+					dists = append(dists, descriptorDistance(current[i].Descriptor, current[j].Descriptor))
+				}
+			}
+			sort.Float64s(dists)
+			current[i].Novelty = Mean(dists[:min(k, len(dists))])
+		}
+
+		novelty := map[string]float64{}
+		for _, c := range current {
+			novelty[strconv.Itoa(c.Variant)] = c.Novelty
+		}
+		for i := range results {
+			if n, ok := novelty[results[i].Variant]; ok {
+				results[i].Selection = &n
+			}
+		}
+
+		sorted := append([]ArchiveEntry(nil), current...)
+		sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Novelty > sorted[b].Novelty })
+		add := d.ArchiveAdd
+		if add <= 0 {
+			add = defaultArchiveAdd
+		}
+		for i, c := range sorted {
+			if (d.ArchiveThreshold > 0 && c.Novelty >= d.ArchiveThreshold) || (d.ArchiveThreshold <= 0 && i < add) {
+				archive.Entries = append(archive.Entries, c)
+			}
+		}
+
+	case DiversityMapElites:
+		cells := map[string]int{}
+		for i, entry := range archive.Entries {
+			cells[entry.Cell] = i
+		}
+		for _, c := range current {
+			c.Cell = mapElitesCell(d, c.Descriptor)
+			if i, ok := cells[c.Cell]; !ok {
+				cells[c.Cell] = len(archive.Entries)
+				archive.Entries = append(archive.Entries, c)
+			} else if prev := archive.Entries[i]; c.Fitness > prev.Fitness {
+				// keep only the elite that held the cell before this generation
+				if prev.Generation == gen {
+					c.Displaced = prev.Displaced
+				} else {
+					prev.Displaced = nil
+					c.Displaced = &prev
+				}
+				archive.Entries[i] = c
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown diversity.mode %q", d.Mode)
+	}
+
+	archive.Kind, archive.Descriptor = kind, d.DescriptorName()
+	return saveArchive(archive)
+}
+
+// mapElitesParents returns the grid's elites as candidate parents, best
+// first.
+func mapElitesParents(numType, mode string) ([]rankedParent, error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := LoadArchive(numType, mode)
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]rankedParent, 0, len(archive.Entries))
+	for _, entry := range archive.Entries {
+		parents = append(parents, rankedParent{Path: entry.Path, Score: entry.Fitness})
+	}
+	sort.SliceStable(parents, func(i, j int) bool { return parents[i].Score > parents[j].Score })
+	return parents, nil
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/OpenFluke/discover"
 )
 
+// shutdownGrace bounds how long SIGTERM waits for the loop to clean up
+const shutdownGrace = 30 * time.Second
+
 // GlobalNetworks holds all constructed networks — accessible from anywhere
 var (
 	GlobalNetworks []NamedNetwork
@@ -56,6 +64,18 @@ func main() {
 		fmt.Printf("Sample spawn points around %s: %v\n", firstPlanet, spawnPoints)
 	}*/
 
+	if len(os.Args) > 1 && os.Args[1] == "mockserver" {
+		if err := runMockServer(os.Args[2:]); err != nil {
+			fmt.Println("❌ Mock server failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	controller.SetContext(ctx)
+
 	cfg, err := LoadExperimentConfig("experiment_config.json")
 	if err != nil {
 		fmt.Println("❌ Failed to load experiment config:", err)
@@ -64,6 +84,7 @@ func main() {
 		fmt.Printf("   Description: %s\n", cfg.Description)
 		fmt.Printf("   Numerical Types: %v\n", cfg.NumericalTypes)
 		fmt.Printf("   Planets: %v\n", cfg.Planets)
+		fmt.Printf("   Optimizer: %s (%d variants per generation)\n", cfg.OptimizerName(), cfg.VariantCount())
 		fmt.Printf("   Spectrum: %d steps, max stddev %.4f\n", cfg.SpectrumSteps, cfg.SpectrumMaxStdDev)
 		fmt.Println("   Auto-launch enabled?", cfg.AutoLaunch)
 
@@ -71,15 +92,29 @@ func main() {
 		ensureInitialModelSetup(cfg)
 	}
 
-	if cfg.AutoState {
+	if cfg != nil && cfg.AutoState {
 		// Try to load existing best model state
 		//fmt.Println("Auto starting")
-		go RunEpisodeLoop(experimentConfig)
+		if err := controller.Start(experimentConfig); err != nil {
+			fmt.Println("❌ Failed to auto-start episode loop:", err)
+		}
 	}
 
 	go startWebSocketServer() // Starts WebSocket server on port 9001
 	go startStatusPoller()
 	go startStatusBroadcastLoop()
 
-	host()
+	go host()
+
+	<-ctx.Done()
+	fmt.Println("🛑 Shutdown requested — stopping episode loop...")
+	if done := controller.Done(); done != nil {
+		select {
+		case <-done:
+			fmt.Println("✅ Episode loop exited cleanly")
+		case <-time.After(shutdownGrace):
+			fmt.Println("⚠️ Episode loop did not exit in time")
+		}
+	}
+	flushStatusUpdates()
 }
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+const defaultESLearningRate = 0.01
+
+// ESState is the search distribution for one type/mode at the start of a
+// generation: variant i is Center + Sigma·ε_i, with ε_i regenerated from
+// its seed. Center only covers the parameters apply_noise_to selects; the
+// rest stay as in the generation-0 model.
+type ESState struct {
+	Generation int       `json:"generation"`
+	NumType    string    `json:"num_type"`
+	Mode       string    `json:"mode"`
+	Sigma      float64   `json:"sigma"` // noise scale in parameter units
+	Center     []float64 `json:"center"`
+}
+
+func esStatePath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "es", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+// esCenterPath is the center as a loadable model, the parent of every
+// variant in the generation.
+func esCenterPath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "es", fmt.Sprintf("%s_%s_center.json", numType, mode))
+}
+
+func loadESState(gen int, numType, mode string) (*ESState, error) {
+	data, err := os.ReadFile(esStatePath(gen, numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var s ESState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func saveESState(s *ESState) error {
+	path := esStatePath(s.Generation, s.NumType, s.Mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// esDraw is the seed and sign behind a variant's ε. With antithetic
+// sampling variants 2k and 2k+1 share one draw with opposite signs.
+func esDraw(cfg *ExperimentConfig, gen int, numType, mode string, variant int) (int64, float64) {
+	draw, sign := variant, 1.0
+	if cfg.ES.Antithetic {
+		draw = variant / 2
+		if variant%2 == 1 {
+			sign = -1
+		}
+	}
+	return variantSeed(cfg.Seed, gen, numType, mode, draw), sign
+}
+
+// esNoise regenerates ε for a variant.
+func esNoise(cfg *ExperimentConfig, gen int, numType, mode string, variant, dim int) []float64 {
+	seed, sign := esDraw(cfg, gen, numType, mode, variant)
+	rng := rand.New(rand.NewSource(seed))
+	eps := make([]float64, dim)
+	for j := range eps {
+		eps[j] = sign * rng.NormFloat64()
+	}
+	return eps
+}
+
+// shapeFitness maps raw scores to utilities. "rank" (default) uses centered
+// ranks in [-0.5, 0.5], "zscore" standardizes, "none" keeps the raw scores.
+func shapeFitness(scores []float64, shaping string) []float64 {
+	n := len(scores)
+	out := make([]float64, n)
+	switch strings.ToLower(shaping) {
+	case "none":
+		copy(out, scores)
+	case "zscore":
+		mean := Mean(scores)
+		std := 0.0
+		for _, s := range scores {
+			std += (s - mean) * (s - mean)
+		}
+		std = math.Sqrt(std / float64(n))
+		for i, s := range scores {
+			if std > 0 {
+				out[i] = (s - mean) / std
+			}
+		}
+	default:
+		if n == 1 {
+			return out
+		}
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] < scores[order[b]] })
+		for rank, i := range order {
+			out[i] = float64(rank)/float64(n-1) - 0.5
+		}
+	}
+	return out
+}
+
+// evaluatedVariants returns the variants of a generation that were scored,
+// with their selection score, restricted to indices below count.
+func evaluatedVariants(gen int, numType, mode string, count int) ([]int, []float64, error) {
+	ranked, err := loadRankedResults(gen, numType, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	var variants []int
+	var scores []float64
+	for _, r := range ranked {
+		v, err := strconv.Atoi(r.Variant)
+		if err != nil || v < 0 || v >= count {
+			continue
+		}
+		variants = append(variants, v)
+		scores = append(scores, r.SelectionScore())
+	}
+	if len(variants) == 0 {
+		return nil, nil, fmt.Errorf("no scored variants in generation %d for %s_%s", gen, numType, mode)
+	}
+	return variants, scores, nil
+}
+
+// esState loads this generation's distribution, or builds it: generation 0
+// centers on the base model, later generations take one gradient step from
+// the previous generation's state using every scored variant.
+func (e *Experiment[T, M]) esState(template *paragon.Network[T], refs []paramRef) (*ESState, error) {
+	mode := e.Mode.String()
+	if s, err := loadESState(e.Gen, e.NumType, mode); err == nil {
+		if len(s.Center) != len(refs) {
+			return nil, fmt.Errorf("es state has %d parameters, network has %d", len(s.Center), len(refs))
+		}
+		return s, nil
+	}
+
+	if e.Gen == 0 {
+		sigma := e.Config.SpectrumMaxStdDev
+		if _, _, integer := numericRange[T](); integer {
+			sigma *= integerNoiseScale
+		}
+		return &ESState{Generation: 0, NumType: e.NumType, Mode: mode, Sigma: sigma, Center: getParams(template, refs)}, nil
+	}
+
+	prev, err := loadESState(e.Gen-1, e.NumType, mode)
+	if err != nil {
+		return nil, fmt.Errorf("previous es state: %w", err)
+	}
+	if len(prev.Center) != len(refs) {
+		return nil, fmt.Errorf("es state has %d parameters, network has %d", len(prev.Center), len(refs))
+	}
+	variants, scores, err := evaluatedVariants(e.Gen-1, e.NumType, mode, e.Config.VariantCount())
+	if err != nil {
+		return nil, err
+	}
+
+	lr := e.Config.ES.LearningRate
+	if lr <= 0 {
+		lr = defaultESLearningRate
+	}
+	utilities := shapeFitness(scores, e.Config.ES.FitnessShaping)
+	grad := make([]float64, len(refs))
+	for k, v := range variants {
+		eps := esNoise(e.Config, e.Gen-1, e.NumType, mode, v, len(refs))
+		for j := range grad {
+			grad[j] += utilities[k] * eps[j]
+		}
+	}
+
+	next := &ESState{Generation: e.Gen, NumType: e.NumType, Mode: mode, Sigma: prev.Sigma, Center: make([]float64, len(refs))}
+	scale := lr / (float64(len(variants)) * prev.Sigma)
+	for j := range next.Center {
+		next.Center[j] = prev.Center[j] + scale*grad[j]
+	}
+	return next, nil
+}
+
+// generateES samples this generation's variants around the ES center.
+func (e *Experiment[T, M]) generateES(ctx context.Context) {
+	mode := e.Mode.String()
+	template, refs, err := e.searchTemplate()
+	if err != nil {
+		fmt.Printf("❌ ES setup failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+
+	state, err := e.esState(template, refs)
+	if err != nil {
+		fmt.Printf("❌ ES update failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	if err := saveESState(state); err != nil {
+		fmt.Printf("❌ Failed to save ES state: %v\n", err)
+		return
+	}
+	e.reportSigma(state.Sigma, OptimizerES)
+
+	centerPath := esCenterPath(e.Gen, e.NumType, mode)
+	vals := make([]float64, len(refs))
+	err = e.writeSamples(ctx, template, refs, state.Center, centerPath, func(i int) ([]float64, VariantRecord) {
+		eps := esNoise(e.Config, e.Gen, e.NumType, mode, i, len(refs))
+		for j := range vals {
+			vals[j] = state.Center[j] + state.Sigma*eps[j]
+		}
+		seed, sign := esDraw(e.Config, e.Gen, e.NumType, mode, i)
+		rec := VariantRecord{Source: "es", Seed: seed, StdDev: state.Sigma}
+		if sign < 0 {
+			rec.Source = "es_mirrored" // ε is the negated draw
+		}
+		return vals, rec
+	})
+	if err != nil {
+		fmt.Printf("❌ ES sampling failed for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	fmt.Printf("💾 ES generation %d for %s_%s sampled around %s (σ=%.4f)\n", e.Gen, e.NumType, mode, centerPath, state.Sigma)
+}
@@ -2,8 +2,10 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,7 +15,6 @@ import (
 	"time"
 
 	paragon "github.com/OpenFluke/PARAGON"
-	"github.com/OpenFluke/construct"
 	"github.com/OpenFluke/discover"
 )
 
@@ -41,28 +42,30 @@ func (ReplayMode) String() string        { return "Replay" }
 func (DynamicReplayMode) String() string { return "DynamicReplay" }
 
 type Experiment[T Numeric, M ExperimentMode] struct {
-	NumType    string
-	Mode       M
-	Config     *ExperimentConfig
-	Gen        int
-	Cubes      []*construct.Cube[T]
-	ServerAddr string
-	AuthPass   string
-	Delimiter  string
+	NumType string
+	Mode    M
+	Config  *ExperimentConfig
+	Gen     int
+	Cubes   []*AgentCube[T]
+	Sim     SimBackend
+
+	spawnSeed int64 // seed of the most recent spawnCubes layout
 }
 
 type ExperimentRunner interface {
 	SetGeneration(gen int)
-	GenerateVariants()
+	GenerateVariants(ctx context.Context)
 	SpawnAgentNames()
-	SpawnAgentsOnPlanets(variantNum int)
-	UnfreezeAgents()
-	RunAndMonitorAgents(variantNum int)
+	SpawnAgentsOnPlanets(ctx context.Context, variantNum int)
+	UnfreezeAgents(ctx context.Context)
+	RunAndMonitorAgents(ctx context.Context, variantNum int)
 	DespawnAgents()
 	NukeAllAgents()
 	GetNumType() string
 	GetMode() string
-	AggregateVariantResults()
+	AggregateVariantResults(ctx context.Context)
+	EvaluateHeldOut(ctx context.Context)
+	OnPod(sim SimBackend) ExperimentRunner
 }
 
 var bestPerExperiment []struct {
@@ -76,35 +79,35 @@ func (e *Experiment[T, M]) SetGeneration(gen int) {
 	e.Gen = gen
 }
 
-func (e *Experiment[T, M]) GenerateVariants() {
+func (e *Experiment[T, M]) GenerateVariants(ctx context.Context) {
 	// your logic
 	fmt.Println(e.Gen, e.NumType+e.Mode.String())
+	e.reportCurriculum()
+	switch e.Config.OptimizerName() {
+	case OptimizerPopulation:
+		e.generatePopulation(ctx)
+		return
+	case OptimizerES:
+		e.generateES(ctx)
+		return
+	case OptimizerCMAES:
+		e.generateCMAES(ctx)
+		return
+	}
+
 	var modelPath string
 
 	if e.Gen == 0 {
 		modelPath = filepath.Join("models", strconv.Itoa(e.Gen), fmt.Sprintf("%s_%s.json", e.NumType, e.Mode.String()))
 	} else {
-		// Load top-performing variant from previous generation
-		totalResultsPath := filepath.Join("models", strconv.Itoa(e.Gen-1), "total_results", fmt.Sprintf("%s_%s.json", e.NumType, e.Mode.String()))
-		data, err := os.ReadFile(totalResultsPath)
+		// Load top-ranked variant from previous generation (by fitness, or
+		// by the diversity.mode selection score)
+		parents, err := rankedParents(e.Config, e.Gen-1, e.NumType, e.Mode.String())
 		if err != nil {
 			fmt.Printf("❌ Could not read prior top results: %v\n", err)
 			return
 		}
-
-		var ranked []struct {
-			Variant      string  `json:"variant"`
-			MeanProgress float64 `json:"mean_progress"`
-		}
-		if err := json.Unmarshal(data, &ranked); err != nil || len(ranked) == 0 {
-			fmt.Printf("❌ Failed to parse top variant from: %s\n", totalResultsPath)
-			return
-		}
-
-		topVariant := ranked[0].Variant
-		modelPath = filepath.Join("models", strconv.Itoa(e.Gen-1),
-			fmt.Sprintf("mutated_%s_%s", e.NumType, e.Mode.String()),
-			fmt.Sprintf("variant_%s.json", topVariant))
+		modelPath = parents[0].Path
 	}
 
 	fmt.Println(modelPath)
@@ -118,11 +121,19 @@ func (e *Experiment[T, M]) GenerateVariants() {
 	champPath := filepath.Join("models", "champion",
 		fmt.Sprintf("%s_%s.json", e.NumType, e.Mode.String()))
 	if data, err := os.ReadFile(champPath); err == nil {
-		_ = os.WriteFile(filepath.Join(mutatedDir, "variant_0.json"), data, 0644)
+		if err := writeFileAtomic(filepath.Join(mutatedDir, "variant_0.json"), data, 0644); err == nil {
+			_ = RecordVariant(e.Gen, e.Config.Seed, VariantRecord{
+				NumType: e.NumType,
+				Mode:    e.Mode.String(),
+				Variant: 0,
+				Source:  "champion",
+				Parent:  champPath,
+			})
+		}
 	}
 
 	// Skip if all already exist
-	if hasAllVariants(mutatedDir, e.Config.SpectrumSteps) {
+	if hasAllVariants(mutatedDir, e.Config.VariantCount()) {
 		fmt.Printf("✅ All variants already exist in %s\n", mutatedDir)
 	}
 
@@ -139,8 +150,42 @@ func (e *Experiment[T, M]) GenerateVariants() {
 		return
 	}
 
+	sigma, err := e.generationSigma(modelPath)
+	if err != nil {
+		fmt.Printf("❌ Step size for %s_%s: %v\n", e.NumType, e.Mode.String(), err)
+		return
+	}
+	e.reportSigma(sigma, e.Config.StepSize.AdaptationName())
+
+	// 🧬 With crossover on, the last slots are children of the top two variants
+	ms := e.Config.MutationStrategy
+	cx := e.Config.Crossover
+	crossFrom := e.Config.VariantCount()
+	var mate *paragon.Network[T]
+	var matePath string
+	if n := min(cx.Children(crossFrom), crossFrom-1); n > 0 && e.Gen > 0 {
+		parents, err := rankedParents(e.Config, e.Gen-1, e.NumType, e.Mode.String())
+		if err == nil && len(parents) > 1 {
+			if mate, err = loadNetworkAs[T](parents[1].Path); err == nil && !sameArchitecture(net, mate) {
+				err = fmt.Errorf("%s has a different architecture", parents[1].Path)
+			}
+			if err == nil {
+				matePath = parents[1].Path
+				crossFrom -= n
+			}
+		}
+		if err != nil {
+			fmt.Printf("⚠️ Crossover skipped for %s_%s: %v\n", e.NumType, e.Mode.String(), err)
+		}
+	}
+
 	// Generate variants
-	for i := 0; i < e.Config.SpectrumSteps; i++ {
+	for i := 0; i < e.Config.VariantCount(); i++ {
+		if ctx.Err() != nil {
+			fmt.Printf("🛑 Variant generation cancelled for %s_%s\n", e.NumType, e.Mode.String())
+			return
+		}
+
 		savePath := filepath.Join(mutatedDir, fmt.Sprintf("variant_%d.json", i))
 
 		// 💡 Skip if already exists
@@ -149,19 +194,44 @@ func (e *Experiment[T, M]) GenerateVariants() {
 			continue
 		}
 
-		// 🧬 Clone and mutate
-		var clone paragon.Network[T]
-		if err := clone.FromS(net.ToS()); err != nil {
-			fmt.Printf("❌ Failed to clone base model for variant %d: %v\n", i, err)
-			continue
+		// 🧬 Clone and mutate (or breed) with a seed unique to this gen/type/mode/variant
+		seed := variantSeed(e.Config.Seed, e.Gen, e.NumType, e.Mode.String(), i)
+		rec := VariantRecord{
+			NumType:  e.NumType,
+			Mode:     e.Mode.String(),
+			Variant:  i,
+			Source:   "mutated",
+			Parent:   modelPath,
+			Seed:     seed,
+			StdDev:   e.childSigma(sigma, seed),
+			Strategy: ms,
 		}
-		clone.PerturbWeights(e.Config.SpectrumMaxStdDev, i)
+		var clone *paragon.Network[T]
+		var err error
+		if i >= crossFrom {
+			clone, err = deriveCrossover(net, mate, cx, ms, rec.StdDev, seed)
+			rec.Source, rec.CoParent, rec.Operator = "crossover", matePath, cx.OperatorName()
+		} else {
+			clone, rec.Structural, err = deriveVariant(net, ms, rec.StdDev, seed)
+			if !ms.ReuseBestModel {
+				rec.Source = "reinit"
+			}
+		}
+		if err != nil {
+			fmt.Printf("❌ Mutation failed for variant %d: %v\n", i, err)
+			return
+		}
+		rec.Architecture = architectureOf(clone)
 
 		// 💾 Save
-		if err := clone.SaveJSON(savePath); err != nil {
+		if err := saveNetworkAtomic(clone, savePath); err != nil {
 			fmt.Printf("❌ Variant %d failed to save: %v\n", i, err)
-		} else {
-			fmt.Printf("💾 Saved variant: %s\n", savePath)
+			continue
+		}
+		fmt.Printf("💾 Saved variant: %s\n", savePath)
+
+		if err := RecordVariant(e.Gen, e.Config.Seed, rec); err != nil {
+			fmt.Printf("⚠️ Could not record variant %d in manifest: %v\n", i, err)
 		}
 	}
 
@@ -203,7 +273,8 @@ func (e *Experiment[T, M]) SpawnAgentNames() {
 		fullPath := filepath.Join(mutatedDir, name)
 		var unitNames []string
 
-		for _, planetStr := range e.Config.Planets {
+		_, stage := e.evaluationStage()
+		for _, planetStr := range stage.PlanetList(e.Config.TrainingPlanets()) {
 			for i := 0; i < e.Config.EvaluationSpawnsPerPlanet; i++ {
 				unitName := discover.GenerateUnitID(fullPath, "openfluke.com", e.Gen, len(unitNames))
 				unitNames = append(unitNames, unitName)
@@ -216,7 +287,7 @@ func (e *Experiment[T, M]) SpawnAgentNames() {
 			fmt.Printf("❌ Failed to marshal names: %v\n", err)
 			continue
 		}
-		if err := os.WriteFile(namesFile, data, 0644); err != nil {
+		if err := writeFileAtomic(namesFile, data, 0644); err != nil {
 			fmt.Printf("❌ Failed to write names file: %v\n", err)
 		} else {
 			fmt.Printf("💾 Saved unit names: %s\n", namesFile)
@@ -224,7 +295,7 @@ func (e *Experiment[T, M]) SpawnAgentNames() {
 	}
 }
 
-func (e *Experiment[T, M]) SpawnAgentsOnPlanets(variantNum int) {
+func (e *Experiment[T, M]) SpawnAgentsOnPlanets(ctx context.Context, variantNum int) {
 	namesPath := filepath.Join(
 		"models",
 		strconv.Itoa(e.Gen),
@@ -245,22 +316,6 @@ func (e *Experiment[T, M]) SpawnAgentsOnPlanets(variantNum int) {
 		return
 	}
 
-	totalPlanets := len(e.Config.Planets)
-	spawnsPerPlanet := e.Config.EvaluationSpawnsPerPlanet
-	expected := totalPlanets * spawnsPerPlanet
-
-	if len(unitNames) < expected {
-		fmt.Printf("⚠️ Warning: Not enough unit names (%d provided, %d expected)\n", len(unitNames), expected)
-	}
-
-	const planetSpacing = 800.0
-	const spawnRadius = 120.0
-	idx := 0
-
-	var cubes []*construct.Cube[T]
-	var cubesMu sync.Mutex
-	var wg sync.WaitGroup
-
 	// Load model for this variant
 	modelPath := filepath.Join(
 		"models",
@@ -281,7 +336,33 @@ func (e *Experiment[T, M]) SpawnAgentsOnPlanets(variantNum int) {
 		return
 	}
 
-	for _, planetStr := range e.Config.Planets {
+	_, stage := e.evaluationStage()
+	seed := e.Config.Randomization.spawnSeed(e.Config.Seed, e.Gen, e.NumType, e.Mode.String(), variantNum)
+	e.beginSession(variantNum)
+	e.Cubes = e.spawnCubes(ctx, net, stage.PlanetList(e.Config.TrainingPlanets()), unitNames, stage, seed)
+}
+
+// spawnCubes places a copy of net on each planet, spawns_per_planet times
+// around a Fibonacci sphere of the stage's radius (randomized from seed
+// when configured), naming the cubes from unitNames in order.
+func (e *Experiment[T, M]) spawnCubes(ctx context.Context, net *paragon.Network[T], planets, unitNames []string, stage CurriculumStage, seed int64) []*AgentCube[T] {
+	spawnsPerPlanet := e.Config.EvaluationSpawnsPerPlanet
+	expected := len(planets) * spawnsPerPlanet
+
+	if len(unitNames) < expected {
+		fmt.Printf("⚠️ Warning: Not enough unit names (%d provided, %d expected)\n", len(unitNames), expected)
+	}
+
+	idx := 0
+
+	var cubes []*AgentCube[T]
+	var cubesMu sync.Mutex
+	var wg sync.WaitGroup
+
+	e.spawnSeed = seed
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, planetStr := range planets {
 		pos, err := parseVec3(planetStr)
 		if err != nil {
 			fmt.Printf("⚠️ Invalid planet string %q: %v\n", planetStr, err)
@@ -293,29 +374,43 @@ func (e *Experiment[T, M]) SpawnAgentsOnPlanets(variantNum int) {
 			pos.Y * planetSpacing,
 			pos.Z * planetSpacing,
 		}
-		positions := discover.FibonacciSphere(spawnsPerPlanet, spawnRadius, center)
+		positions, goals := spawnLayout(e.Config.Randomization, stage, center, spawnsPerPlanet, rng)
 
 		fmt.Printf("🌍 Planet: %s (center: %.2f, %.2f, %.2f)\n", planetStr, center[0], center[1], center[2])
 
 		for i := 0; i < spawnsPerPlanet && idx < len(unitNames); i++ {
+			if ctx.Err() != nil {
+				break
+			}
 			name := unitNames[idx]
-			spawn := positions[i]
+			spawn, goal := positions[i], goals[i]
 			idx++
 
-			cube := &construct.Cube[T]{
-				Name:       name,
-				UnitName:   "AutoUnit",
-				Position:   spawn,
-				Model:      net,
-				ServerAddr: e.ServerAddr,
-				AuthPass:   e.AuthPass,
-				Delimiter:  e.Delimiter,
-				ClampMin:   -20.0,
-				ClampMax:   20.0,
+			// Each cube pulses concurrently, so each needs its own copy of the model
+			model, err := cloneNetwork(net)
+			if err != nil {
+				fmt.Printf("❌ Failed to clone model for %s: %v\n", name, err)
+				continue
+			}
+
+			cube := &AgentCube[T]{
+				Name:        name,
+				UnitName:    "AutoUnit",
+				Position:    spawn,
+				Model:       model,
+				Sim:         e.Sim,
+				ForceClamp:  movementClamp(e.Config.Movement.Translation.Clamp),
+				TorqueClamp: e.Config.Movement.Rotation.Clamp,
+
+				Planet:       planetStr,
+				PlanetCenter: center,
+				SpawnPos:     append([]float64(nil), spawn...),
+				Goal:         goal,
+				Observer:     newObserver(e.Config.Observation, e.Config.Movement.Lifespan()),
 			}
 
 			wg.Add(1)
-			go func(c *construct.Cube[T], planet string, pos []float64) {
+			go func(c *AgentCube[T], planet string, pos []float64) {
 				defer wg.Done()
 				if err := c.Spawn(); err != nil {
 					fmt.Printf("❌ Spawn failed for %s: %v\n", c.Name, err)
@@ -337,24 +432,24 @@ func (e *Experiment[T, M]) SpawnAgentsOnPlanets(variantNum int) {
 		fmt.Printf("⚠️ %d unit names were unused\n", len(unitNames)-idx)
 	}
 
-	e.Cubes = cubes
+	return cubes
 }
 
-func (e *Experiment[T, M]) UnfreezeAgents() {
+func (e *Experiment[T, M]) UnfreezeAgents(ctx context.Context) {
 	if len(e.Cubes) == 0 {
 		fmt.Println("⚠️ No cubes to unfreeze.")
 		return
 	}
-
-	construct := &construct.Construct[T]{
-		ServerAddr: e.ServerAddr,
-		AuthPass:   e.AuthPass,
-		Delimiter:  e.Delimiter,
+	if ctx.Err() != nil {
+		return
+	}
+	if err := e.Sim.UnfreezeAll(); err != nil {
+		fmt.Printf("❌ Failed to unfreeze agents: %v\n", err)
 	}
-	construct.UnfreezeAll()
 }
 
 func (e *Experiment[T, M]) DespawnAgents() {
+	defer e.endSession()
 	if len(e.Cubes) == 0 {
 		fmt.Println("⚠️ No cubes to despawn.")
 		return
@@ -377,13 +472,10 @@ func (e *Experiment[T, M]) DespawnAgents() {
 func (e *Experiment[T, M]) NukeAllAgents() {
 	fmt.Println("💥 Nuking all agents on the server...")
 
-	construct := &construct.Construct[T]{
-		ServerAddr: e.ServerAddr,
-		AuthPass:   e.AuthPass,
-		Delimiter:  e.Delimiter,
+	if err := e.Sim.DestroyAll(); err != nil {
+		fmt.Printf("❌ Failed to destroy agents: %v\n", err)
 	}
-
-	construct.DestroyAllCubes()
+	e.endSession()
 
 	// Clear cube references just in case
 	e.Cubes = nil
@@ -402,18 +494,11 @@ func ParseExperimentMode(modeStr string) (ExperimentMode, error) {
 	}
 }
 
-func CreateExperiments(cfg *ExperimentConfig) []ExperimentRunner {
+// CreateExperiments builds one experiment per type and mode on sim; the
+// episode loop moves each variant onto a pod with OnPod.
+func CreateExperiments(cfg *ExperimentConfig, sim SimBackend) []ExperimentRunner {
 	var all []ExperimentRunner
 
-	// Default server connection setup
-	host := os.Getenv("GAME_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-	serverAddr := host + ":14000"
-	authPass := "my_secure_password"
-	delimiter := "<???DONE???---"
-
 	for _, numType := range cfg.NumericalTypes {
 		for _, modeStr := range cfg.Modes {
 			mode, err := ParseExperimentMode(modeStr)
@@ -425,37 +510,35 @@ func CreateExperiments(cfg *ExperimentConfig) []ExperimentRunner {
 			switch numType {
 			case "int":
 				all = append(all, &Experiment[int, ExperimentMode]{
-					NumType:    numType,
-					Mode:       mode,
-					Config:     cfg,
-					ServerAddr: serverAddr,
-					AuthPass:   authPass,
-					Delimiter:  delimiter,
+					NumType: numType,
+					Mode:    mode,
+					Config:  cfg,
+					Sim:     sim,
 				})
 			case "int8":
-				all = append(all, &Experiment[int8, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[int8, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "int16":
-				all = append(all, &Experiment[int16, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[int16, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "int32":
-				all = append(all, &Experiment[int32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[int32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "int64":
-				all = append(all, &Experiment[int64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[int64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 
 			case "uint":
-				all = append(all, &Experiment[uint, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[uint, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "uint8":
-				all = append(all, &Experiment[uint8, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[uint8, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "uint16":
-				all = append(all, &Experiment[uint16, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[uint16, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "uint32":
-				all = append(all, &Experiment[uint32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[uint32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "uint64":
-				all = append(all, &Experiment[uint64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[uint64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 
 			case "float32":
-				all = append(all, &Experiment[float32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[float32, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 			case "float64":
-				all = append(all, &Experiment[float64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, ServerAddr: serverAddr, AuthPass: authPass, Delimiter: delimiter})
+				all = append(all, &Experiment[float64, ExperimentMode]{NumType: numType, Mode: mode, Config: cfg, Sim: sim})
 
 			default:
 				fmt.Printf("⚠️ Unknown numeric type: %s\n", numType)
@@ -466,16 +549,74 @@ func CreateExperiments(cfg *ExperimentConfig) []ExperimentRunner {
 	return all
 }
 
-func (e *Experiment[T, M]) RunAndMonitorAgents(variantNum int) {
+// OnPod is a copy of e evaluating on sim, so several variants can run on
+// different pods at once.
+func (e *Experiment[T, M]) OnPod(sim SimBackend) ExperimentRunner {
+	c := *e
+	c.Sim = sim
+	c.Cubes = nil
+	return &c
+}
+
+func (e *Experiment[T, M]) RunAndMonitorAgents(ctx context.Context, variantNum int) {
 	if len(e.Cubes) == 0 {
 		fmt.Println("⚠️ No agents to run.")
 		return
 	}
 
+	var recorder *trajectoryRecorder
+	if e.Config.Trajectory.Enabled {
+		recorder = newTrajectoryRecorder(e.Config.Trajectory, e.Gen, e.NumType, e.Mode.String(), variantNum)
+	}
+
+	summary, samples, err := e.scoreCubes(ctx, recorder)
+	if err != nil {
+		fmt.Printf("🛑 Pulsing interrupted for variant %d: %v — discarding partial results\n", variantNum, err)
+		return
+	}
+	if index, _ := e.evaluationStage(); index >= 0 {
+		summary["curriculum_stage"] = index
+	}
+	if e.Config.Diversity.ModeName() != DiversityFitness {
+		summary["behavior"] = behaviorDescriptor(e.Config.Diversity.DescriptorName(), samples)
+	}
+
+	// Save
+	resultsDir := filepath.Join("models", strconv.Itoa(e.Gen),
+		fmt.Sprintf("mutated_%s_%s", e.NumType, e.Mode.String()), "results")
+	_ = os.MkdirAll(resultsDir, 0755)
+
+	summaryPath := filepath.Join(resultsDir, fmt.Sprintf("variant_%d_summary.json", variantNum))
+
+	if err := writeFileAtomic(summaryPath, mustMarshalIndent(summary), 0644); err != nil {
+		fmt.Printf("❌ Failed to write summary: %v\n", err)
+	} else {
+		fmt.Printf("✅ Saved progress summary: %s\n", summaryPath)
+	}
+
+	if recorder != nil {
+		trajectoryPath := filepath.Join(resultsDir, fmt.Sprintf("variant_%d_trajectory.json", variantNum))
+		data, err := recorder.Marshal()
+		if err == nil {
+			err = writeFileAtomic(trajectoryPath, data, 0644)
+		}
+		if err != nil {
+			fmt.Printf("❌ Failed to write trajectory: %v\n", err)
+		} else {
+			fmt.Printf("🛰️ Saved trajectory: %s\n", trajectoryPath)
+		}
+	}
+}
+
+// scoreCubes pulses e.Cubes for the configured lifespan and scores them,
+// returning the summary and each cube's start and end for the behavior
+// descriptors. recorder, when non-nil, samples their trajectories.
+func (e *Experiment[T, M]) scoreCubes(ctx context.Context, recorder *trajectoryRecorder) (map[string]any, []behaviorSample, error) {
 	type result struct {
 		Name         string
 		Planet       string
 		PlanetCenter []float64
+		Spawn        []float64
 		Goal         []float64
 		InitialPos   []float64
 		FinalPos     []float64
@@ -483,107 +624,167 @@ func (e *Experiment[T, M]) RunAndMonitorAgents(variantNum int) {
 		FinalDist    float64
 		Progress     float64
 		DeltaY       float64
+		Survival     float64
+		Energy       float64
+		TimedOut     bool
+		Checkpoints  int
+		Score        float64
 	}
 
-	planetSpacing := 800.0
-	topOffset := []float64{0, 100, 0} // goal above center
+	scoring := e.Config.Scoring
+	scorer := scorerForConfig(scoring)
 
 	initialPos := make(map[string][]float64)
 	planetLookup := make(map[string]string)
 	goalLookup := make(map[string][]float64)
+	centerLookup := make(map[string][]float64)
 
-	// Prepare mappings
-	idx := 0
-	for _, planetStr := range e.Config.Planets {
-		planetPos, err := parseVec3(planetStr)
-		if err != nil {
-			continue
-		}
-		center := []float64{
-			planetPos.X * planetSpacing,
-			planetPos.Y * planetSpacing,
-			planetPos.Z * planetSpacing,
-		}
-		goal := []float64{
-			center[0] + topOffset[0],
-			center[1] + topOffset[1],
-			center[2] + topOffset[2],
+	// Prepare mappings from what each cube was spawned with (cubes are
+	// appended as their spawns complete, so their order says nothing)
+	for _, cube := range e.Cubes {
+		initialPos[cube.Name] = append([]float64{}, cube.Position...)
+		planetLookup[cube.Name] = cube.Planet
+		goalLookup[cube.Name] = cube.Goal
+		centerLookup[cube.Name] = cube.PlanetCenter
+	}
+
+	// Per-tick work: accumulate_over_life scoring and checkpoint sampling
+	duration := e.Config.Movement.Lifespan()
+	var tickHandlers []func(elapsed time.Duration)
+
+	accumulate := scoring.EvaluateEveryTick && scoring.AccumulateOverLife
+	accumulated := make(map[string]float64)
+	if accumulate {
+		tickHandlers = append(tickHandlers, func(elapsed time.Duration) {
+			for _, cube := range e.Cubes {
+				vars := newScoreVars(initialPos[cube.Name], cube.Position, goalLookup[cube.Name],
+					centerLookup[cube.Name], elapsed.Seconds(), duration.Seconds())
+				accumulated[cube.Name] += applyScore(scorer, scoring, vars)
+			}
+		})
+	}
+
+	checkpoints := newCheckpointTracker(e.Config)
+	if e.Config.EnableCheckpointing {
+		for _, cube := range e.Cubes {
+			checkpoints.Start(cube.Name, initialPos[cube.Name], goalLookup[cube.Name])
 		}
+		tickHandlers = append(tickHandlers, func(elapsed time.Duration) {
+			for _, cube := range e.Cubes {
+				checkpoints.Observe(cube.Name, elapsed.Seconds(), cube.Position, goalLookup[cube.Name])
+			}
+		})
+	}
 
-		for i := 0; i < e.Config.EvaluationSpawnsPerPlanet; i++ {
-			if idx >= len(e.Cubes) {
-				break
+	if recorder != nil {
+		tickHandlers = append(tickHandlers, func(elapsed time.Duration) {
+			if !recorder.Due(elapsed) {
+				return
+			}
+			for _, cube := range e.Cubes {
+				recorder.Record(cube.Name, elapsed, cube.Position, cube.LastOutput)
+			}
+		})
+	}
+
+	var onTick func(elapsed time.Duration)
+	if len(tickHandlers) > 0 {
+		onTick = func(elapsed time.Duration) {
+			for _, h := range tickHandlers {
+				h(elapsed)
 			}
-			cube := e.Cubes[idx]
-			initialPos[cube.Name] = append([]float64{}, cube.Position...)
-			planetLookup[cube.Name] = planetStr
-			goalLookup[cube.Name] = goal
-			idx++
 		}
 	}
 
-	// Create construct and run pulsing
-	c := &construct.Construct[T]{
-		ServerAddr: e.ServerAddr,
-		AuthPass:   e.AuthPass,
-		Delimiter:  e.Delimiter,
-		Cubes:      e.Cubes,
+	// Run pulsing
+	fmt.Printf("⚡ Pulsing agents for %v (scorer: %s)...\n", duration, scorer.Name())
+	survival, err := pulseCubes(ctx, e.Cubes, e.Config.PulseMovement(), e.Config.Stepped(), onTick)
+	if err != nil {
+		return nil, nil, err
 	}
-	duration := 10 * time.Second
-	fmt.Printf("⚡ Pulsing agents for %v...\n", duration)
-	c.StartPulsing(10, duration)
 
 	// Evaluate progress
 	var results []result
 	var progresses []float64
 
 	for _, cube := range e.Cubes {
-		_ = cube.RefreshPosition()
+		timedOut := cube.RefreshPosition() != nil
 		start := initialPos[cube.Name]
 		end := cube.Position
 		goal := goalLookup[cube.Name]
 		planet := planetLookup[cube.Name]
 
-		initialDist := distance(start, goal)
-		finalDist := distance(end, goal)
-		progress := initialDist - finalDist
+		vars := newScoreVars(start, end, goal, centerLookup[cube.Name],
+			survival[cube.Name].Seconds(), duration.Seconds())
+
+		if e.Config.EnableCheckpointing && !timedOut {
+			checkpoints.Observe(cube.Name, duration.Seconds(), end, goal)
+		}
+
+		// a timed-out cube scores nothing, checkpoint reward included
+		var score float64
+		switch {
+		case timedOut && !scoring.ScoreIfTimeout:
+			score = 0
+		case accumulate:
+			score = accumulated[cube.Name]
+		default:
+			score = applyScore(scorer, scoring, vars)
+		}
+		if e.Config.EnableCheckpointing && (!timedOut || scoring.ScoreIfTimeout) {
+			score += checkpoints.Reward(cube.Name)
+		}
 
 		results = append(results, result{
 			Name:         cube.Name,
 			Planet:       planet,
-			PlanetCenter: goal, // technically goal is above center, but fine here
+			PlanetCenter: centerLookup[cube.Name],
+			Spawn:        cube.SpawnPos,
 			Goal:         goal,
 			InitialPos:   start,
 			FinalPos:     end,
-			InitialDist:  initialDist,
-			FinalDist:    finalDist,
-			Progress:     progress,
+			InitialDist:  vars.InitialDist,
+			FinalDist:    vars.FinalDist,
+			Progress:     vars.InitialDist - vars.FinalDist,
 			DeltaY:       end[1] - start[1],
+			Survival:     vars.Survival,
+			Energy:       cube.Energy,
+			TimedOut:     timedOut,
+			Checkpoints:  checkpoints.Count(cube.Name),
+			Score:        score,
 		})
-		progresses = append(progresses, progress)
+		progresses = append(progresses, score)
 	}
 
-	// Build summary
+	objectiveSamples := make([]objectiveSample, 0, len(results))
+	for _, r := range results {
+		objectiveSamples = append(objectiveSamples, objectiveSample{
+			Planet: r.Planet, Score: r.Score, Energy: r.Energy, Survival: r.Survival, DeltaY: r.DeltaY,
+		})
+	}
+
+	// Build summary (mean_progress keeps its name so aggregation and the
+	// dashboard are untouched; it now holds the configured score)
 	summary := map[string]any{
+		"scorer":          scorer.Name(),
 		"mean_progress":   Mean(progresses),
 		"median_progress": Median(progresses),
 		"max_progress":    Max(progresses),
 		"min_progress":    Min(progresses),
+		"objectives":      variantObjectives(objectiveSamples),
 		"results":         results,
 	}
-
-	// Save
-	resultsDir := filepath.Join("models", strconv.Itoa(e.Gen),
-		fmt.Sprintf("mutated_%s_%s", e.NumType, e.Mode.String()), "results")
-	_ = os.MkdirAll(resultsDir, 0755)
-
-	summaryPath := filepath.Join(resultsDir, fmt.Sprintf("variant_%d_summary.json", variantNum))
-
-	if err := os.WriteFile(summaryPath, mustMarshalIndent(summary), 0644); err != nil {
-		fmt.Printf("❌ Failed to write summary: %v\n", err)
-	} else {
-		fmt.Printf("✅ Saved progress summary: %s\n", summaryPath)
+	if e.Config.EnableCheckpointing && e.Config.Evaluation.SaveCheckpointHits {
+		summary["checkpoint_hits"] = checkpoints.Hits()
+	}
+	if e.Config.Randomization.Enabled {
+		summary["randomization_seed"] = e.spawnSeed
 	}
+	samples := make([]behaviorSample, 0, len(results))
+	for _, r := range results {
+		samples = append(samples, behaviorSample{Planet: r.Planet, Start: r.InitialPos, End: r.FinalPos})
+	}
+	return summary, samples, nil
 }
 
 func mustMarshalIndent(v any) []byte {
@@ -602,7 +803,11 @@ func (e *Experiment[T, M]) GetMode() string {
 	return e.Mode.String()
 }
 
-func (e *Experiment[T, M]) AggregateVariantResults() {
+func (e *Experiment[T, M]) AggregateVariantResults(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	resultsDir := filepath.Join("models", strconv.Itoa(e.Gen),
 		fmt.Sprintf("mutated_%s_%s", e.NumType, e.Mode.String()), "results")
 
@@ -611,15 +816,12 @@ func (e *Experiment[T, M]) AggregateVariantResults() {
 
 	if _, err := os.Stat(outputPath); err == nil {
 		fmt.Printf("📄 Aggregated results already exist: %s — skipping\n", outputPath)
+		syncPopulationFitness(e.Gen, e.NumType, e.Mode.String())
 		return
 	}
 
-	type rankedResult struct {
-		Variant      string  `json:"variant"`
-		MeanProgress float64 `json:"mean_progress"`
-	}
-
 	var results []rankedResult
+	descriptors := map[string][]float64{}
 
 	entries, err := os.ReadDir(resultsDir)
 	if err != nil {
@@ -652,10 +854,29 @@ func (e *Experiment[T, M]) AggregateVariantResults() {
 			continue
 		}
 
-		results = append(results, rankedResult{
-			Variant:      strings.TrimSuffix(strings.TrimPrefix(name, "variant_"), "_summary.json"),
+		variant := strings.TrimSuffix(strings.TrimPrefix(name, "variant_"), "_summary.json")
+		r := rankedResult{
+			Variant:      variant,
 			MeanProgress: meanVal,
-		})
+		}
+		if raw, ok := summary["objectives"].(map[string]any); ok {
+			r.Objectives = make(map[string]float64, len(raw))
+			for k, v := range raw {
+				if f, ok := v.(float64); ok {
+					r.Objectives[k] = f
+				}
+			}
+		}
+		results = append(results, r)
+		if raw, ok := summary["behavior"].([]any); ok {
+			desc := make([]float64, 0, len(raw))
+			for _, v := range raw {
+				if f, ok := v.(float64); ok {
+					desc = append(desc, f)
+				}
+			}
+			descriptors[variant] = desc
+		}
 	}
 
 	if len(results) == 0 {
@@ -666,6 +887,15 @@ func (e *Experiment[T, M]) AggregateVariantResults() {
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].MeanProgress > results[j].MeanProgress
 	})
+	if e.Config.Objectives.SelectionName() == SelectionNSGA2 {
+		if err := rankNSGA2(results, e.Config.Objectives.ObjectiveNames()); err != nil {
+			fmt.Printf("⚠️ NSGA-II ranking skipped for %s_%s, keeping the mean_progress order: %v\n", e.NumType, e.Mode.String(), err)
+		}
+	}
+
+	if err := applyDiversity(e.Config, e.Gen, e.NumType, e.Mode.String(), results, descriptors); err != nil {
+		fmt.Printf("⚠️ Diversity archive not updated for %s_%s: %v\n", e.NumType, e.Mode.String(), err)
+	}
 
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		fmt.Printf("❌ Failed to create output directory: %v\n", err)
@@ -678,15 +908,20 @@ func (e *Experiment[T, M]) AggregateVariantResults() {
 		return
 	}
 
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	if err := writeFileAtomic(outputPath, data, 0644); err != nil {
 		fmt.Printf("❌ Failed to write aggregated results: %v\n", err)
 		return
 	}
 
 	fmt.Printf("✅ Saved ordered results for %s_%s → %s\n", e.NumType, e.Mode.String(), outputPath)
+	syncPopulationFitness(e.Gen, e.NumType, e.Mode.String())
 }
 
-func SaveFullResultsIfNotExists(gen int) {
+func SaveFullResultsIfNotExists(ctx context.Context, gen int) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	totalResultsDir := filepath.Join("models", strconv.Itoa(gen), "total_results")
 	fullResultsPath := filepath.Join(totalResultsDir, "full_results.json")
 
@@ -702,10 +937,12 @@ func SaveFullResultsIfNotExists(gen int) {
 	}
 
 	type topResult struct {
-		NumType      string  `json:"num_type"`
-		Mode         string  `json:"mode"`
-		VariantIndex string  `json:"variant"`
-		Score        float64 `json:"mean_progress"`
+		NumType      string             `json:"num_type"`
+		Mode         string             `json:"mode"`
+		VariantIndex string             `json:"variant"`
+		Score        float64            `json:"mean_progress"`
+		Objectives   map[string]float64 `json:"objectives,omitempty"`
+		Front        int                `json:"front,omitempty"`
 	}
 
 	var topVariants []topResult
@@ -731,21 +968,36 @@ func SaveFullResultsIfNotExists(gen int) {
 			continue
 		}
 
-		var variants []struct {
-			Variant      string  `json:"variant"`
-			MeanProgress float64 `json:"mean_progress"`
-		}
+		var variants []rankedResult
 		if err := json.Unmarshal(data, &variants); err != nil || len(variants) == 0 {
 			fmt.Printf("⚠️ Failed to parse or empty: %s\n", name)
 			continue
 		}
 
-		top := variants[0]
+		// NSGA-II results report their whole first front, scalar ones their best
+		if variants[0].Front > 0 {
+			for _, v := range variants {
+				if v.Front == 1 {
+					topVariants = append(topVariants, topResult{
+						NumType:      numType,
+						Mode:         mode,
+						VariantIndex: v.Variant,
+						Score:        v.MeanProgress,
+						Objectives:   v.Objectives,
+						Front:        v.Front,
+					})
+				}
+			}
+			continue
+		}
+
+		top := fittestResult(variants)
 		topVariants = append(topVariants, topResult{
 			NumType:      numType,
 			Mode:         mode,
 			VariantIndex: top.Variant,
 			Score:        top.MeanProgress,
+			Objectives:   top.Objectives,
 		})
 	}
 
@@ -764,7 +1016,7 @@ func SaveFullResultsIfNotExists(gen int) {
 		return
 	}
 
-	if err := os.WriteFile(fullResultsPath, data, 0644); err != nil {
+	if err := writeFileAtomic(fullResultsPath, data, 0644); err != nil {
 		fmt.Printf("❌ Failed to write full_results.json: %v\n", err)
 		return
 	}
@@ -772,27 +1024,28 @@ func SaveFullResultsIfNotExists(gen int) {
 	fmt.Printf("✅ Saved full_results.json for Gen %d → %s\n", gen, fullResultsPath)
 }
 
-func UpdateChampionIfBetter(gen int, numType string, mode string) {
+func UpdateChampionIfBetter(ctx context.Context, gen int, numType string, mode string) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	championPath := filepath.Join("models", "champion", fmt.Sprintf("%s_%s.json", numType, mode))
-	bestFromGenPath := filepath.Join("models", strconv.Itoa(gen), "total_results", fmt.Sprintf("%s_%s.json", numType, mode))
 
-	data, err := os.ReadFile(bestFromGenPath)
+	ranked, err := loadRankedResults(gen, numType, mode)
 	if err != nil {
 		fmt.Printf("❌ Could not read best result file for %s_%s\n", numType, mode)
 		return
 	}
-
-	var ranked []struct {
-		Variant      string  `json:"variant"`
-		MeanProgress float64 `json:"mean_progress"`
-	}
-	if err := json.Unmarshal(data, &ranked); err != nil || len(ranked) == 0 {
+	if len(ranked) == 0 {
 		fmt.Printf("⚠️ Could not parse best variant for %s_%s\n", numType, mode)
 		return
 	}
 
-	newScore := ranked[0].MeanProgress
-	newVariant := ranked[0].Variant
+	// the champion is always the fittest variant, even when total_results
+	// is ordered by Pareto front
+	best := fittestResult(ranked)
+	newScore := best.MeanProgress
+	newVariant := best.Variant
 	newModelPath := filepath.Join("models", strconv.Itoa(gen),
 		fmt.Sprintf("mutated_%s_%s", numType, mode),
 		fmt.Sprintf("variant_%s.json", newVariant))
@@ -831,6 +1084,13 @@ func UpdateChampionIfBetter(gen int, numType string, mode string) {
 						fmt.Sprintf("variant_%s.json", entry.Variant))
 
 					if champModel, err := os.ReadFile(champPathFromGen); err == nil && string(champModel) == string(champData) {
+						// a champion from an easier curriculum stage is replaced, not compared
+						champStage, ok1 := recordedStage(g, numType, mode)
+						newStage, ok2 := recordedStage(gen, numType, mode)
+						if ok1 && ok2 && champStage < newStage {
+							fmt.Printf("🎓 Champion for %s_%s was scored at curriculum stage %d — replacing it at stage %d\n", numType, mode, champStage+1, newStage+1)
+							break
+						}
 						if entry.MeanProgress > newScore {
 							fmt.Printf("⚖️ Champion still better (%.4f > %.4f) — skipping update for %s_%s\n", entry.MeanProgress, newScore, numType, mode)
 							overwrite = false
@@ -847,7 +1107,7 @@ func UpdateChampionIfBetter(gen int, numType string, mode string) {
 
 	if overwrite {
 		_ = os.MkdirAll(filepath.Dir(championPath), 0755)
-		if err := os.WriteFile(championPath, newModelData, 0644); err != nil {
+		if err := writeFileAtomic(championPath, newModelData, 0644); err != nil {
 			fmt.Printf("❌ Failed to write new champion: %v\n", err)
 		} else {
 			fmt.Printf("👑 Updated champion for %s_%s → variant %s (score: %.4f)\n", numType, mode, newVariant, newScore)
@@ -855,74 +1115,59 @@ func UpdateChampionIfBetter(gen int, numType string, mode string) {
 	}
 }
 
-func RunEpisodeLoop(cfg *ExperimentConfig) {
-	all := CreateExperiments(cfg)
+func RunEpisodeLoop(ctx context.Context, cfg *ExperimentConfig) {
+	pods, err := newSimPods(cfg)
+	if err != nil || len(pods) == 0 {
+		fmt.Printf("❌ Could not start simulator: %v\n", err)
+		return
+	}
+	setActiveSims(podSims(pods))
+	fmt.Printf("🧪 Simulator: %s on %d pod(s), %d variant(s) per pod\n", cfg.SimName(), len(pods), pods[0].slots)
+
+	all := CreateExperiments(cfg, pods[0].sim)
+
+	// cancelled despawns whatever the interrupted experiment left on the server
+	cancelled := func(exp ExperimentRunner, gen, variant int) bool {
+		if ctx.Err() == nil {
+			return false
+		}
+		exp.DespawnAgents()
+		AppendStatus(gen, exp.GetNumType(), exp.GetMode(), variant, "Cancelled", "Episode loop cancelled — agents despawned")
+		return true
+	}
 
 	for gen := 0; gen < cfg.Episodes; gen++ {
 		for _, exp := range all {
 			AppendStatus(gen, exp.GetNumType(), exp.GetMode(), -1, "Generating", "Starting new generation")
 
 			exp.SetGeneration(gen)
-			exp.GenerateVariants()
+			exp.GenerateVariants(ctx)
+			if cancelled(exp, gen, -1) {
+				return
+			}
 
 			AppendStatus(gen, exp.GetNumType(), exp.GetMode(), -1, "Generated", "Variants created")
 
 			exp.SpawnAgentNames()
-			for i := 0; i < cfg.SpectrumSteps; i++ {
-
-				numType := exp.GetNumType()
-				mode := exp.GetMode()
-
-				summaryPath := filepath.Join("models", strconv.Itoa(gen),
-					fmt.Sprintf("mutated_%s_%s", numType, mode),
-					"results", fmt.Sprintf("variant_%d_summary.json", i))
-
-				if _, err := os.Stat(summaryPath); err == nil {
-					AppendStatus(gen, numType, mode, i, "Skipped", "Summary already exists")
-					continue
-				}
-
-				if _, err := os.Stat(summaryPath); err == nil {
-					fmt.Printf("⏩ Skipping variant %d for %s_%s — summary already exists\n", i, numType, mode)
-
-					//runDuration := 50 * time.Second
-					//fmt.Printf("⏳ Letting agents run for %s...\n", runDuration)
-					//time.Sleep(runDuration)
-
-					continue
-				}
-
-				AppendStatus(gen, numType, mode, i, "SpawningAgents", "Spawning agents for variant")
-
-				exp.SpawnAgentsOnPlanets(i)
-				exp.UnfreezeAgents()
-
-				AppendStatus(gen, numType, mode, i, "Running", "Agents running...")
-
-				// 🕒 Allow agents to run for some time before despawning
-				/*runDuration := 5 * time.Second
-				fmt.Printf("⏳ Letting agents run for %s...\n", runDuration)
-				time.Sleep(runDuration)*/
-				exp.RunAndMonitorAgents(i)
-
-				AppendStatus(gen, numType, mode, i, "Finished", "Run and monitor completed")
-
-				exp.NukeAllAgents()
-
-				AppendStatus(gen, numType, mode, i, "Cleaned", "Agents nuked")
-
-				//exp.DespawnAgents()
-				//exP.RunExperiment()
-				//exp.Despawn
+			if !evaluateVariants(ctx, cfg, exp, pods, gen) {
+				return
 			}
 
 			// ⏫ After all variants for this Experiment are done, aggregate results
-			exp.AggregateVariantResults()
-			UpdateChampionIfBetter(gen, exp.GetNumType(), exp.GetMode())
-		}
-		SaveFullResultsIfNotExists(gen)
-		//break
+			exp.AggregateVariantResults(ctx)
+			UpdateChampionIfBetter(ctx, gen, exp.GetNumType(), exp.GetMode())
+			if cancelled(exp, gen, -1) {
+				return
+			}
 
+			// 🧪 Held-out planets score the champion only; selection never sees them
+			heldOut := exp.OnPod(firstHealthyPod(pods).sim)
+			heldOut.EvaluateHeldOut(ctx)
+			if cancelled(heldOut, gen, -1) {
+				return
+			}
+		}
+		SaveFullResultsIfNotExists(ctx, gen)
 	}
 }
 
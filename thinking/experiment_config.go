@@ -2,39 +2,277 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
+	"strings"
+	"time"
 )
 
 // Top-level config
 type ExperimentConfig struct {
-	Name                      string           `json:"name"`
-	Description               string           `json:"description"`
-	Modes                     []string         `json:"modes"`
-	NumericalTypes            []string         `json:"numerical_types"`
-	Planets                   []string         `json:"planets"`
-	Episodes                  int              `json:"episodes"`
-	CheckpointReward          int              `json:"checkpoint_reward"`
-	EnableCheckpointing       bool             `json:"enable_checkpointing"`
-	SpectrumSteps             int              `json:"spectrum_steps"`
-	SpectrumMaxStdDev         float64          `json:"spectrum_max_stddev"`
-	MutationStrategy          MutationStrategy `json:"mutation_strategy"`
-	NetworkConfig             NetworkConfig    `json:"network_config"`
-	Movement                  MovementConfig   `json:"movement"`
-	Scoring                   ScoringConfig    `json:"scoring"`
-	Evaluation                EvaluationConfig `json:"evaluation"`
-	AutoLaunch                bool             `json:"auto_launch"`
-	Notes                     string           `json:"notes"`
-	AutoState                 bool             `json:"auto_state"`
-	EvaluationSpawnsPerPlanet int              `json:"evaluation_spawns_per_planet"`
-	MaxNeeded                 int              `json:"max_needed"`
-	LoadBalance               bool             `json:"load_balance"`
+	Name                      string              `json:"name"`
+	Seed                      int64               `json:"seed"` // master seed for variant mutation
+	Description               string              `json:"description"`
+	Modes                     []string            `json:"modes"`
+	NumericalTypes            []string            `json:"numerical_types"`
+	Planets                   []string            `json:"planets"`
+	TrainPlanets              []string            `json:"train_planets"` // planets variants are selected on; defaults to planets
+	TestPlanets               []string            `json:"test_planets"`  // held out: only champions are evaluated here
+	TestEvery                 int                 `json:"test_every"`    // held-out evaluation every N generations (default 1)
+	Episodes                  int                 `json:"episodes"`
+	CheckpointReward          int                 `json:"checkpoint_reward"`
+	EnableCheckpointing       bool                `json:"enable_checkpointing"`
+	CheckpointSpacing         float64             `json:"checkpoint_spacing"`
+	SpectrumSteps             int                 `json:"spectrum_steps"`
+	SpectrumMaxStdDev         float64             `json:"spectrum_max_stddev"`
+	StepSize                  StepSizeConfig      `json:"step_size"`
+	Optimizer                 string              `json:"optimizer"` // "spectrum" (default), "population", "es" or "cmaes"
+	Population                PopulationConfig    `json:"population"`
+	Crossover                 CrossoverConfig     `json:"crossover"`
+	ES                        ESConfig            `json:"es"`
+	CMAES                     CMAESConfig         `json:"cmaes"`
+	Diversity                 DiversityConfig     `json:"diversity"`
+	Objectives                ObjectivesConfig    `json:"objectives"`
+	MutationStrategy          MutationStrategy    `json:"mutation_strategy"`
+	NetworkConfig             NetworkConfig       `json:"network_config"`
+	Movement                  MovementConfig      `json:"movement"`
+	Scoring                   ScoringConfig       `json:"scoring"`
+	Evaluation                EvaluationConfig    `json:"evaluation"`
+	Curriculum                CurriculumConfig    `json:"curriculum"`
+	Randomization             RandomizationConfig `json:"randomization"`
+	Observation               ObservationConfig   `json:"observation"`
+	AutoLaunch                bool                `json:"auto_launch"`
+	Notes                     string              `json:"notes"`
+	AutoState                 bool                `json:"auto_state"`
+	EvaluationSpawnsPerPlanet int                 `json:"evaluation_spawns_per_planet"`
+	MaxNeeded                 int                 `json:"max_needed"` // most cubes a local pod hosts at once; 0 means one variant per pod
+	LoadBalance               bool                `json:"load_balance"`
+	Trajectory                TrajectoryConfig    `json:"trajectory"`
+	Sim                       string              `json:"sim"` // "primordia" (default), "local" or "replay"
+	LocalSim                  LocalSimConfig      `json:"local_sim"`
+	MockServer                MockServerConfig    `json:"mock_server"`
+	Sessions                  SessionConfig       `json:"sessions"`
+	Pods                      PodsConfig          `json:"pods"`
+}
+
+// Optimizers selectable with "optimizer"
+const (
+	OptimizerSpectrum   = "spectrum"
+	OptimizerPopulation = "population"
+	OptimizerES         = "es"
+	OptimizerCMAES      = "cmaes"
+)
+
+// OptimizerName is the configured optimizer, defaulting to the original
+// single-parent spectrum.
+func (c *ExperimentConfig) OptimizerName() string {
+	if c.Optimizer == "" {
+		return OptimizerSpectrum
+	}
+	return strings.ToLower(c.Optimizer)
+}
+
+// VariantCount is how many variants each generation evaluates.
+func (c *ExperimentConfig) VariantCount() int {
+	if c.OptimizerName() == OptimizerPopulation {
+		return c.Population.SizeOrDefault(c.SpectrumSteps)
+	}
+	return c.SpectrumSteps
+}
+
+// TrainingPlanets are the planets variants are scored on for selection.
+func (c *ExperimentConfig) TrainingPlanets() []string {
+	if len(c.TrainPlanets) > 0 {
+		return c.TrainPlanets
+	}
+	return c.Planets
+}
+
+// AllPlanets is every configured planet (planets, train_planets and
+// test_planets) once each, in that order.
+func (c *ExperimentConfig) AllPlanets() []string {
+	var all []string
+	seen := map[string]bool{}
+	for _, list := range [][]string{c.Planets, c.TrainPlanets, c.TestPlanets} {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				all = append(all, name)
+			}
+		}
+	}
+	return all
+}
+
+// HeldOutDue reports whether gen's champions are evaluated on test_planets.
+func (c *ExperimentConfig) HeldOutDue(gen int) bool {
+	if len(c.TestPlanets) == 0 {
+		return false
+	}
+	return gen%max(c.TestEvery, 1) == 0
 }
 
 // Nested structs
 type MutationStrategy struct {
-	ApplyNoiseTo   string `json:"apply_noise_to"`
-	NoiseType      string `json:"noise_type"`
-	ReuseBestModel bool   `json:"reuse_best_model"`
+	ApplyNoiseTo   string             `json:"apply_noise_to"`
+	NoiseType      string             `json:"noise_type"`
+	ReuseBestModel bool               `json:"reuse_best_model"`
+	SparseFraction float64            `json:"sparse_fraction"` // share of parameters perturbed by noise_type "sparse"
+	Structural     StructuralMutation `json:"structural"`
+}
+
+// StructuralMutation grows and prunes the architecture. Each variant gets
+// at most one structural change, on top of its weight noise.
+type StructuralMutation struct {
+	Rate            float64  `json:"rate"`        // chance of a structural change per variant; 0 disables
+	Operators       []string `json:"operators"`   // default all
+	Activations     []string `json:"activations"` // change_activation candidates
+	MaxHiddenLayers int      `json:"max_hidden_layers"`
+	MaxWidth        int      `json:"max_width"`
+}
+
+type PopulationConfig struct {
+	Size              int     `json:"size"`
+	EliteCount        int     `json:"elite_count"`
+	Selection         string  `json:"selection"` // "tournament" (default) or "rank"
+	TournamentSize    int     `json:"tournament_size"`
+	SelectionPressure float64 `json:"selection_pressure"` // rank selection, 1 (uniform) to 2
+}
+
+// SizeOrDefault falls back to spectrum_steps so switching optimizer keeps
+// the same evaluation budget.
+func (p PopulationConfig) SizeOrDefault(fallback int) int {
+	if p.Size > 0 {
+		return p.Size
+	}
+	return fallback
+}
+
+type CrossoverConfig struct {
+	Enabled        bool    `json:"enabled"`
+	Operator       string  `json:"operator"`        // "uniform" (default), "neuron" or "layer"
+	Rate           float64 `json:"rate"`            // share of children bred by crossover
+	MutateChildren bool    `json:"mutate_children"` // also apply mutation_strategy noise to them
+}
+
+// ESConfig tunes the evolution-strategies optimizer. The noise scale is
+// spectrum_max_stddev.
+type ESConfig struct {
+	LearningRate   float64 `json:"learning_rate"`
+	Antithetic     bool    `json:"antithetic"`      // mirrored ±ε pairs
+	FitnessShaping string  `json:"fitness_shaping"` // "rank" (default), "zscore" or "none"
+}
+
+type CMAESConfig struct {
+	// searches with more parameters than this keep only a diagonal covariance
+	FullCovarianceMax int `json:"full_covariance_max"`
+}
+
+// DiversityConfig chooses how parents are ranked: by fitness, by novelty
+// against a behavior archive, or from a MAP-Elites grid.
+type DiversityConfig struct {
+	Mode             string       `json:"mode"`              // "fitness" (default), "novelty" or "map_elites"
+	Descriptor       string       `json:"descriptor"`        // "displacement" (default), "planet_displacement" or "reach"
+	K                int          `json:"k"`                 // novelty: nearest neighbours averaged
+	ArchiveThreshold float64      `json:"archive_threshold"` // novelty: archive every variant at least this novel
+	ArchiveAdd       int          `json:"archive_add"`       // novelty: else archive this many most novel per generation
+	Bins             int          `json:"bins"`              // map_elites: cells per descriptor dimension
+	Ranges           [][2]float64 `json:"ranges"`            // map_elites: [min, max] per dimension
+}
+
+// ObjectivesConfig picks how aggregation orders variants: by mean_progress
+// alone, or NSGA-II over several objectives.
+type ObjectivesConfig struct {
+	Selection string   `json:"selection"` // "scalar" (default) or "nsga2"
+	Use       []string `json:"use"`       // objectives nsga2 ranks on; default all
+}
+
+// CurriculumConfig evaluates on progressively harder stages instead of the
+// full planet list from the start.
+type CurriculumConfig struct {
+	Enabled bool              `json:"enabled"`
+	Stages  []CurriculumStage `json:"stages"`
+}
+
+type CurriculumStage struct {
+	Planets     int     `json:"planets"`      // first N of planets; 0 means all
+	SpawnRadius float64 `json:"spawn_radius"` // 0 means 120
+	GoalOffset  float64 `json:"goal_offset"`  // goal height above the planet center; 0 means 100
+	PromoteAt   float64 `json:"promote_at"`   // best mean_progress that moves on to the next stage
+}
+
+// LocalSimConfig tunes the in-process simulator used with "sim": "local".
+type LocalSimConfig struct {
+	PlanetRadius float64            `json:"planet_radius"` // surface radius of every planet; 0 means 50
+	PlanetRadii  map[string]float64 `json:"planet_radii"`  // per-planet overrides, keyed like planets
+	Gravity      float64            `json:"gravity"`       // pull at the surface; 0 means 2
+	Mass         float64            `json:"mass"`          // cube mass; 0 means 1
+	Damping      float64            `json:"damping"`       // velocity lost per second; 0 means 0.1
+	Realtime     bool               `json:"realtime"`      // pulse on the wall clock instead of stepping as fast as possible
+}
+
+// MockServerConfig tunes the mock Primordia server started with
+// "thinking mockserver". Its world is a LocalWorld built from local_sim.
+type MockServerConfig struct {
+	Addr      string  `json:"addr"`       // listen address; empty means :14000
+	Password  string  `json:"password"`   // empty means the server's default
+	LatencyMs int     `json:"latency_ms"` // delay before handling every command
+	JitterMs  int     `json:"jitter_ms"`  // plus up to this much, drawn from seed
+	FailRate  float64 `json:"fail_rate"`  // chance a command drops the connection
+	DropRate  float64 `json:"drop_rate"`  // chance a command is silently ignored
+	Seed      int64   `json:"seed"`       // seeds jitter and failures; 0 uses the experiment seed
+}
+
+// SessionConfig records simulator sessions, one file per evaluated
+// variant, and says where "sim": "replay" reads them back from.
+type SessionConfig struct {
+	Record    bool    `json:"record"`    // capture every simulator call and response
+	Dir       string  `json:"dir"`       // sessions live under <dir>/<gen>/sessions; empty means models
+	Tolerance float64 `json:"tolerance"` // replay: largest argument difference that is not a divergence; 0 means 1e-6
+}
+
+// PodsConfig lists the simulator instances variants are sharded across.
+// Primordia pods are every host at start_port + i*port_step for i below
+// num_pods; other simulators start num_pods independent instances.
+type PodsConfig struct {
+	Hosts        []string `json:"hosts"`         // empty means GAME_HOST (default localhost)
+	StartPort    int      `json:"start_port"`    // 0 means 14000
+	PortStep     int      `json:"port_step"`     // 0 means 3
+	NumPods      int      `json:"num_pods"`      // per host; 0 means 1
+	RetrySeconds int      `json:"retry_seconds"` // rest before probing a pod that stopped responding; 0 means 10
+	MaxAttempts  int      `json:"max_attempts"`  // pods a variant is tried on before it is given up; 0 means 3
+}
+
+// ObservationConfig declares what the network sees at every pulse. The
+// features must add up to network_config.layers[0].
+type ObservationConfig struct {
+	Features      []string `json:"features"`       // see observation.go; empty feeds the raw position
+	Normalize     string   `json:"normalize"`      // "none" (default), "scale" or "tanh"
+	DistanceScale float64  `json:"distance_scale"` // world units that map to 1; 0 means 100
+	VelocityScale float64  `json:"velocity_scale"` // units per second that map to 1; 0 means 10
+}
+
+// RandomizationConfig perturbs where agents spawn and where their goal is,
+// so variants cannot overfit to exact spawn points.
+type RandomizationConfig struct {
+	Enabled          bool    `json:"enabled"`
+	RadiusJitter     float64 `json:"radius_jitter"`      // spawn radius scaled by 1 ± this fraction, per agent
+	RotateSphere     bool    `json:"rotate_sphere"`      // random rotation of each planet's Fibonacci sphere
+	GoalAngle        float64 `json:"goal_angle"`         // max degrees the goal direction tilts from straight up
+	GoalHeightJitter float64 `json:"goal_height_jitter"` // goal offset scaled by 1 ± this fraction, per agent
+	Seed             int64   `json:"seed"`               // 0 uses the experiment seed
+	PerGeneration    bool    `json:"per_generation"`     // one draw per generation, shared by every variant
+}
+
+// StepSizeConfig adapts the mutation stddev, which starts at
+// spectrum_max_stddev.
+type StepSizeConfig struct {
+	Adaptation string  `json:"adaptation"` // "none" (default), "one_fifth" or "self_adaptive"
+	MinSigma   float64 `json:"min_sigma"`
+	MaxSigma   float64 `json:"max_sigma"`
+	Damping    float64 `json:"damping"` // one_fifth: larger is slower
+	Tau        float64 `json:"tau"`     // self_adaptive: log-normal learning rate
 }
 
 type NetworkConfig struct {
@@ -51,6 +289,43 @@ type MovementConfig struct {
 	Rotation    MovementSubConfig `json:"rotation"`
 	MaxLifespan int               `json:"max_lifespan_seconds"`
 }
+
+// Defaults the live evaluation used before movement was configurable
+const (
+	defaultActionsPerSecond = 10
+	defaultLifespanSeconds  = 10
+)
+
+// Lifespan is how long each variant's agents are pulsed.
+func (m MovementConfig) Lifespan() time.Duration {
+	if m.MaxLifespan <= 0 {
+		return defaultLifespanSeconds * time.Second
+	}
+	return time.Duration(m.MaxLifespan) * time.Second
+}
+
+// PulseRate is the translation action rate, which drives the pulse loop.
+func (m MovementConfig) PulseRate() int {
+	if m.Translation.ActionsPerSecond <= 0 {
+		return defaultActionsPerSecond
+	}
+	return m.Translation.ActionsPerSecond
+}
+
+// TorqueEvery is how many pulses pass between rotation actions, or 0 when
+// rotation is disabled.
+func (m MovementConfig) TorqueEvery() int {
+	rot := m.Rotation
+	if rot.ActionsPerSecond <= 0 || (rot.Clamp.X == 0 && rot.Clamp.Y == 0 && rot.Clamp.Z == 0) {
+		return 0
+	}
+	every := int(math.Round(float64(m.PulseRate()) / float64(rot.ActionsPerSecond)))
+	if every < 1 {
+		every = 1
+	}
+	return every
+}
+
 type MovementSubConfig struct {
 	Clamp            Vector3 `json:"clamp"`
 	ActionsPerSecond int     `json:"actions_per_second"`
@@ -72,6 +347,11 @@ type ScoringConfig struct {
 	Notes              string `json:"notes"`
 }
 
+type TrajectoryConfig struct {
+	Enabled      bool    `json:"enabled"`
+	SampleRateHz float64 `json:"sample_rate_hz"`
+}
+
 type EvaluationConfig struct {
 	PerPlanetTracking  bool `json:"per_planet_tracking"`
 	SaveFinalDistances bool `json:"save_final_distances"`
@@ -88,5 +368,11 @@ func LoadExperimentConfig(path string) (*ExperimentConfig, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.Observation.Validate(cfg.NetworkConfig); err != nil {
+		return nil, fmt.Errorf("observation: %w", err)
+	}
+	if err := cfg.Scoring.Validate(); err != nil {
+		return nil, fmt.Errorf("scoring: %w", err)
+	}
 	return &cfg, nil
 }
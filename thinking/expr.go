@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A tiny arithmetic language for scoring.reward_formula. It only knows
+// numbers, named variables, + - * / ^, parentheses and a fixed set of math
+// functions, so a config file can never run arbitrary code.
+
+type exprNode interface {
+	eval(vars map[string]float64) float64
+}
+
+type numNode float64
+type varNode string
+type unaryNode struct {
+	op rune
+	x  exprNode
+}
+type binaryNode struct {
+	op   rune
+	l, r exprNode
+}
+type callNode struct {
+	name string
+	args []exprNode
+}
+
+func (n numNode) eval(map[string]float64) float64 { return float64(n) }
+func (n varNode) eval(vars map[string]float64) float64 {
+	return vars[string(n)]
+}
+
+func (n unaryNode) eval(vars map[string]float64) float64 {
+	if n.op == '-' {
+		return -n.x.eval(vars)
+	}
+	return n.x.eval(vars)
+}
+
+func (n binaryNode) eval(vars map[string]float64) float64 {
+	l, r := n.l.eval(vars), n.r.eval(vars)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	case '/':
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	case '^':
+		return math.Pow(l, r)
+	}
+	return 0
+}
+
+var exprFuncs = map[string]struct {
+	arity int // -1 = at least one argument
+	fn    func(args []float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(math.Max(a[0], 0)) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"log":   {1, func(a []float64) float64 { return math.Log(math.Max(a[0], 1e-12)) }},
+	"min":   {-1, func(a []float64) float64 { return Min(a) }},
+	"max":   {-1, func(a []float64) float64 { return Max(a) }},
+	"clamp": {3, func(a []float64) float64 { return math.Max(a[1], math.Min(a[2], a[0])) }},
+}
+
+func (n callNode) eval(vars map[string]float64) float64 {
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(vars)
+	}
+	return exprFuncs[n.name].fn(args)
+}
+
+// Expr is a compiled reward formula.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// CompileExpr parses src and rejects any variable not listed in allowed.
+func CompileExpr(src string, allowed []string) (*Expr, error) {
+	p := &exprParser{src: src, allowed: make(map[string]bool)}
+	for _, name := range allowed {
+		p.allowed[name] = true
+	}
+	p.next()
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokEOF {
+		return nil, fmt.Errorf("formula %q: unexpected %q at %d", src, p.text, p.start)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the formula; unset variables read as zero.
+func (e *Expr) Eval(vars map[string]float64) float64 {
+	return e.root.eval(vars)
+}
+
+func (e *Expr) String() string { return e.src }
+
+const (
+	tokEOF = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type exprParser struct {
+	src     string
+	pos     int
+	start   int
+	tok     int
+	text    string
+	allowed map[string]bool
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	p.start = p.pos
+	if p.pos >= len(p.src) {
+		p.tok, p.text = tokEOF, ""
+		return
+	}
+
+	c := rune(p.src[p.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// optional exponent, e.g. 1e-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && unicode.IsDigit(rune(p.src[p.pos])) {
+				p.pos++
+			}
+		}
+		p.tok = tokNum
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = tokIdent
+	default:
+		p.pos++
+		p.tok = tokOp
+	}
+	p.text = p.src[p.start:p.pos]
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("formula %q at %d: %s", p.src, p.start, fmt.Sprintf(format, args...))
+}
+
+// sum := product { (+|-) product }
+func (p *exprParser) parseSum() (exprNode, error) {
+	l, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && (p.text == "+" || p.text == "-") {
+		op := rune(p.text[0])
+		p.next()
+		r, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryNode{op, l, r}
+	}
+	return l, nil
+}
+
+// product := unary { (*|/) unary }
+func (p *exprParser) parseProduct() (exprNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && (p.text == "*" || p.text == "/") {
+		op := rune(p.text[0])
+		p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryNode{op, l, r}
+	}
+	return l, nil
+}
+
+// unary := (+|-) unary | power
+//
+// Sign binds looser than ^, so -2^2 is -(2^2).
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.tok == tokOp && (p.text == "-" || p.text == "+") {
+		op := rune(p.text[0])
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op, x}, nil
+	}
+	return p.parsePower()
+}
+
+// power := primary [ ^ unary ]   (right associative)
+func (p *exprParser) parsePower() (exprNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok == tokOp && p.text == "^" {
+		p.next()
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{'^', base, exp}, nil
+	}
+	return base, nil
+}
+
+// primary := number | ident | ident ( args ) | ( sum )
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	switch p.tok {
+	case tokNum:
+		v, err := strconv.ParseFloat(p.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.text)
+		}
+		p.next()
+		return numNode(v), nil
+
+	case tokIdent:
+		name := strings.ToLower(p.text)
+		p.next()
+		if p.tok == tokOp && p.text == "(" {
+			return p.parseCall(name)
+		}
+		if !p.allowed[name] {
+			return nil, p.errorf("unknown variable %q", name)
+		}
+		return varNode(name), nil
+
+	case tokOp:
+		if p.text == "(" {
+			p.next()
+			x, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			if p.tok != tokOp || p.text != ")" {
+				return nil, p.errorf("missing )")
+			}
+			p.next()
+			return x, nil
+		}
+	}
+	if p.tok == tokEOF {
+		return nil, p.errorf("unexpected end of formula")
+	}
+	return nil, p.errorf("unexpected %q", p.text)
+}
+
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	spec, ok := exprFuncs[name]
+	if !ok {
+		return nil, p.errorf("unknown function %q", name)
+	}
+	p.next() // consume (
+
+	var args []exprNode
+	if !(p.tok == tokOp && p.text == ")") {
+		for {
+			arg, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.tok == tokOp && p.text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+	}
+	if p.tok != tokOp || p.text != ")" {
+		return nil, p.errorf("missing ) after %s arguments", name)
+	}
+	p.next()
+
+	if spec.arity < 0 && len(args) == 0 {
+		return nil, p.errorf("%s takes at least one argument", name)
+	}
+	if spec.arity >= 0 && len(args) != spec.arity {
+		return nil, p.errorf("%s takes %d argument(s), got %d", name, spec.arity, len(args))
+	}
+	return callNode{name, args}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	vars := map[string]float64{"dist": 3, "progress": 0.5}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 3 / 2", 2},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"-dist ^ 2", -9},
+		{"(-2) ^ 2", 4},
+		{"2 ^ -1", 0.5},
+		{"- -3", 3},
+		{"+4", 4},
+		{"2 * -3", -6},
+		{"-2 ^ 2 * 3", -12},
+		{"1 / 0", 0},
+		{"1e-3 * 1000", 1},
+		{"progress - 0.1 * dist", 0.2},
+		{"DIST", 3},
+		{"abs(-dist)", 3},
+		{"sqrt(16)", 4},
+		{"min(3, 1, 2)", 1},
+		{"max(dist, 5)", 5},
+		{"clamp(dist, 0, 1)", 1},
+		{"exp(0) + log(1)", 1},
+	}
+	for _, tt := range tests {
+		expr, err := CompileExpr(tt.src, []string{"dist", "progress"})
+		if err != nil {
+			t.Errorf("CompileExpr(%q): %v", tt.src, err)
+			continue
+		}
+		if got := expr.Eval(vars); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"speed * 2", `unknown variable "speed"`},
+		{"floor(dist)", `unknown function "floor"`},
+		{"abs(1, 2)", "abs takes 1 argument(s), got 2"},
+		{"clamp(dist, 0)", "clamp takes 3 argument(s), got 2"},
+		{"min()", "min takes at least one argument"},
+		{"abs(1", "missing ) after abs arguments"},
+		{"(1 + 2", "missing )"},
+		{"1 +", "unexpected end of formula"},
+		{"1 2", `unexpected "2"`},
+		{"1 $ 2", `unexpected "$"`},
+	}
+	for _, tt := range tests {
+		_, err := CompileExpr(tt.src, []string{"dist"})
+		if err == nil {
+			t.Errorf("CompileExpr(%q) succeeded, want error containing %q", tt.src, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("CompileExpr(%q) = %v, want error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	paragon "github.com/OpenFluke/PARAGON"
+	"github.com/OpenFluke/discover"
+)
+
+// GeneralizationPoint is one held-out evaluation of a type/mode's champion.
+type GeneralizationPoint struct {
+	Generation      int     `json:"generation"`
+	Train           float64 `json:"train"`                      // the champion's mean_progress on the training planets
+	Test            float64 `json:"test"`                       // its mean_progress on test_planets
+	ChampionGen     int     `json:"champion_generation"`        // generation the champion was evaluated in
+	ChampionVariant string  `json:"champion_variant,omitempty"` // its variant id there
+}
+
+// GeneralizationCurve is the train-vs-test history of one type/mode, kept
+// at models/generalization/<type>_<mode>.json.
+type GeneralizationCurve struct {
+	NumType     string                `json:"num_type"`
+	Mode        string                `json:"mode"`
+	TestPlanets []string              `json:"test_planets"`
+	Points      []GeneralizationPoint `json:"points"`
+}
+
+var generalizationMu sync.Mutex
+
+func generalizationPath(numType, mode string) string {
+	return filepath.Join("models", "generalization", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+// heldOutSummaryPath sits outside mutated_*/results so aggregation never
+// mistakes it for a variant.
+func heldOutSummaryPath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "heldout", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+func LoadGeneralizationCurve(numType, mode string) (*GeneralizationCurve, error) {
+	data, err := os.ReadFile(generalizationPath(numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var c GeneralizationCurve
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// appendGeneralizationPoint adds p to the curve, replacing an earlier point
+// for the same generation.
+func appendGeneralizationPoint(numType, mode string, testPlanets []string, p GeneralizationPoint) error {
+	generalizationMu.Lock()
+	defer generalizationMu.Unlock()
+
+	curve, err := LoadGeneralizationCurve(numType, mode)
+	if err != nil {
+		curve = &GeneralizationCurve{NumType: numType, Mode: mode}
+	}
+	curve.TestPlanets = testPlanets
+
+	kept := curve.Points[:0]
+	for _, old := range curve.Points {
+		if old.Generation != p.Generation {
+			kept = append(kept, old)
+		}
+	}
+	curve.Points = append(kept, p)
+	sort.Slice(curve.Points, func(i, j int) bool { return curve.Points[i].Generation < curve.Points[j].Generation })
+
+	path := generalizationPath(numType, mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, mustMarshalIndent(curve), 0644)
+}
+
+// LoadAllGeneralizationCurves returns every curve on disk, for the API and
+// dashboard.
+func LoadAllGeneralizationCurves() []*GeneralizationCurve {
+	generalizationMu.Lock()
+	defer generalizationMu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join("models", "generalization"))
+	if err != nil {
+		return nil
+	}
+	var out []*GeneralizationCurve
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(entry.Name(), ".json"), "_", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if c, err := LoadGeneralizationCurve(parts[0], parts[1]); err == nil {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// championOrigin finds the most recent generation (≤ gen) whose variant is
+// byte-identical to the champion, and that variant's training result.
+func championOrigin(champData []byte, gen int, numType, mode string) (int, rankedResult, bool) {
+	for g := gen; g >= 0; g-- {
+		ranked, err := loadRankedResults(g, numType, mode)
+		if err != nil {
+			continue
+		}
+		for _, r := range ranked {
+			path := filepath.Join("models", strconv.Itoa(g),
+				fmt.Sprintf("mutated_%s_%s", numType, mode),
+				fmt.Sprintf("variant_%s.json", r.Variant))
+			if data, err := os.ReadFile(path); err == nil && string(data) == string(champData) {
+				return g, r, true
+			}
+		}
+	}
+	return 0, rankedResult{}, false
+}
+
+// heldOutStage is the geometry champions are tested at: the curriculum's
+// final stage, or the default radius and goal offset, always on every test
+// planet.
+func (c *ExperimentConfig) heldOutStage() CurriculumStage {
+	var stage CurriculumStage
+	if c.Curriculum.Enabled && len(c.Curriculum.Stages) > 0 {
+		stage = c.Curriculum.Stages[len(c.Curriculum.Stages)-1]
+	}
+	stage.Planets = 0
+	return stage
+}
+
+// EvaluateHeldOut scores the type/mode champion on test_planets. The result
+// never feeds selection or the champion comparison; it is written to
+// models/<gen>/heldout/ and appended to the generalization curve.
+func (e *Experiment[T, M]) EvaluateHeldOut(ctx context.Context) {
+	if !e.Config.HeldOutDue(e.Gen) || ctx.Err() != nil {
+		return
+	}
+	mode := e.Mode.String()
+
+	summaryPath := heldOutSummaryPath(e.Gen, e.NumType, mode)
+	if _, err := os.Stat(summaryPath); err == nil {
+		fmt.Printf("✅ Skipping held-out evaluation for %s_%s — summary already exists\n", e.NumType, mode)
+		return
+	}
+
+	championPath := filepath.Join("models", "champion", fmt.Sprintf("%s_%s.json", e.NumType, mode))
+	champData, err := os.ReadFile(championPath)
+	if err != nil {
+		fmt.Printf("⚠️ No champion to test for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	modelAny, err := paragon.LoadNamedNetworkFromJSONFile(championPath)
+	if err != nil {
+		fmt.Printf("❌ Failed to load champion: %v\n", err)
+		return
+	}
+	net, ok := modelAny.(*paragon.Network[T])
+	if !ok {
+		fmt.Printf("⚠️ Type assertion failed for champion: %T\n", modelAny)
+		return
+	}
+
+	planets := e.Config.TestPlanets
+	unitNames := make([]string, 0, len(planets)*e.Config.EvaluationSpawnsPerPlanet)
+	for len(unitNames) < cap(unitNames) {
+		unitNames = append(unitNames, discover.GenerateUnitID(championPath, "openfluke.com", e.Gen, len(unitNames)))
+	}
+
+	AppendStatus(e.Gen, e.NumType, mode, -1, "HeldOut", fmt.Sprintf("Evaluating champion on %d held-out planet(s)", len(planets)))
+	seed := e.Config.Randomization.spawnSeed(e.Config.Seed, e.Gen, e.NumType, mode, -1)
+	e.beginSession(-1)
+	e.Cubes = e.spawnCubes(ctx, net, planets, unitNames, e.Config.heldOutStage(), seed)
+	e.UnfreezeAgents(ctx)
+	if len(e.Cubes) == 0 {
+		fmt.Println("⚠️ No held-out agents spawned.")
+		return
+	}
+	summary, _, err := e.scoreCubes(ctx, nil)
+	if err != nil {
+		fmt.Printf("🛑 Held-out evaluation interrupted for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	e.NukeAllAgents()
+
+	point := GeneralizationPoint{Generation: e.Gen, Test: summary["mean_progress"].(float64)}
+	if g, r, ok := championOrigin(champData, e.Gen, e.NumType, mode); ok {
+		point.Train, point.ChampionGen, point.ChampionVariant = r.MeanProgress, g, r.Variant
+	}
+	summary["test_planets"] = planets
+	summary["train_mean_progress"] = point.Train
+	summary["champion_generation"] = point.ChampionGen
+	summary["champion_variant"] = point.ChampionVariant
+
+	_ = os.MkdirAll(filepath.Dir(summaryPath), 0755)
+	if err := writeFileAtomic(summaryPath, mustMarshalIndent(summary), 0644); err != nil {
+		fmt.Printf("❌ Failed to write held-out summary: %v\n", err)
+		return
+	}
+	if err := appendGeneralizationPoint(e.NumType, mode, planets, point); err != nil {
+		fmt.Printf("❌ Failed to update generalization curve: %v\n", err)
+	}
+
+	msg := fmt.Sprintf("Champion train %.4f / test %.4f", point.Train, point.Test)
+	AppendStatus(e.Gen, e.NumType, mode, -1, "HeldOutDone", msg)
+	fmt.Printf("🧪 %s_%s held-out: %s\n", e.NumType, mode, msg)
+
+	// the episode loop is one more sender, so go through the locked broadcast
+	if data := SerializeTyped(TypeGeneralizationCurve, LoadAllGeneralizationCurves()); data != nil {
+		broadcast(data)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"math"
 	"os"
 	"sort"
+
+	paragon "github.com/OpenFluke/PARAGON"
 )
 
 func Mean(nums []float64) float64 {
@@ -109,3 +112,45 @@ func copyFile(src, dst string) error {
 	in.Close()
 	return os.Rename(tmp, dst) // atomic on POSIX
 }
+
+// writeFileAtomic writes through a temp file so an interrupted run never
+// leaves a half-written JSON file behind.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path) // atomic on POSIX
+}
+
+func saveNetworkAtomic[T paragon.Numeric](net *paragon.Network[T], path string) error {
+	tmp := path + ".tmp"
+	if err := net.SaveJSON(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// cloneNetwork deep-copies a network through its serialisable form.
+func cloneNetwork[T paragon.Numeric](net *paragon.Network[T]) (*paragon.Network[T], error) {
+	clone := &paragon.Network[T]{}
+	if err := clone.FromS(net.ToS()); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// loadNetworkAs loads a saved model and checks it has numeric type T.
+func loadNetworkAs[T paragon.Numeric](path string) (*paragon.Network[T], error) {
+	anyNet, err := paragon.LoadNamedNetworkFromJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+	net, ok := anyNet.(*paragon.Network[T])
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected *Network[%T], got %T", *new(T), anyNet)
+	}
+	return net, nil
+}
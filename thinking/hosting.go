@@ -98,6 +98,24 @@ func host() {
 		return c.Render("layout", data)
 	})
 
+	// Behavior archives (novelty / MAP-Elites) for every type and mode
+	app.Get("/api/archive", func(c *fiber.Ctx) error {
+		archives := LoadAllArchives()
+		if archives == nil {
+			archives = []*BehaviorArchive{}
+		}
+		return c.JSON(archives)
+	})
+
+	// Held-out train-vs-test curves for every type and mode
+	app.Get("/api/generalization", func(c *fiber.Ctx) error {
+		curves := LoadAllGeneralizationCurves()
+		if curves == nil {
+			curves = []*GeneralizationCurve{}
+		}
+		return c.JSON(curves)
+	})
+
 	app.Get("/ws/status", websocket.New(func(c *websocket.Conn) {
 		clientsMu.Lock()
 		clients[c] = true
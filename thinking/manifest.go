@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// VariantRecord is a variant's lineage and everything needed to rebuild it
+// with deriveVariant or deriveCrossover: parents, strategy, stddev and seed.
+type VariantRecord struct {
+	NumType  string           `json:"num_type"`
+	Mode     string           `json:"mode"`
+	Variant  int              `json:"variant"`
+	Source   string           `json:"source"` // how it was made: "mutated", "reinit", "crossover", "elite", "champion"…
+	Parent   string           `json:"parent"`
+	CoParent string           `json:"co_parent,omitempty"` // second parent of a crossover child
+	Operator string           `json:"crossover,omitempty"`
+	Seed     int64            `json:"seed"`
+	StdDev   float64          `json:"stddev"`
+	Strategy MutationStrategy `json:"mutation_strategy"`
+
+	Architecture string `json:"architecture,omitempty"` // see architectureOf
+	Structural   string `json:"structural,omitempty"`   // structural change made to the parent, if any
+}
+
+// GenerationManifest lives at models/<gen>/manifest.json.
+type GenerationManifest struct {
+	Generation int                `json:"generation"`
+	MasterSeed int64              `json:"master_seed"`
+	Sigma      map[string]float64 `json:"sigma,omitempty"`            // step size per "<type>_<mode>"
+	Curriculum map[string]int     `json:"curriculum_stage,omitempty"` // stage index per "<type>_<mode>"
+	Variants   []VariantRecord    `json:"variants"`
+}
+
+var manifestMu sync.Mutex
+
+// variantSeed derives a seed unique to one (generation, type, mode, variant)
+// so no two variants share a noise pattern, yet reruns reproduce them.
+func variantSeed(master int64, gen int, numType, mode string, variant int) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%d|%s|%s|%d", master, gen, numType, mode, variant)
+	return int64(h.Sum64())
+}
+
+func manifestPath(gen int) string {
+	return filepath.Join("models", strconv.Itoa(gen), "manifest.json")
+}
+
+// LoadManifest reads a generation's manifest; a missing file is an empty one.
+func LoadManifest(gen int) (*GenerationManifest, error) {
+	m := &GenerationManifest{Generation: gen}
+	data, err := os.ReadFile(manifestPath(gen))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestPath(gen), err)
+	}
+	return m, nil
+}
+
+// updateManifest applies fn to a generation's manifest under the lock and
+// writes it back.
+func updateManifest(gen int, fn func(m *GenerationManifest)) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	m, err := LoadManifest(gen)
+	if err != nil {
+		return err
+	}
+	fn(m)
+
+	if err := os.MkdirAll(filepath.Dir(manifestPath(gen)), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(manifestPath(gen), data, 0644)
+}
+
+// RecordVariant adds or replaces one variant's entry in its generation's
+// manifest.
+func RecordVariant(gen int, masterSeed int64, rec VariantRecord) error {
+	return updateManifest(gen, func(m *GenerationManifest) {
+		m.MasterSeed = masterSeed
+		for i, existing := range m.Variants {
+			if existing.NumType == rec.NumType && existing.Mode == rec.Mode && existing.Variant == rec.Variant {
+				m.Variants[i] = rec
+				return
+			}
+		}
+		m.Variants = append(m.Variants, rec)
+	})
+}
+
+// RecordSigma stores the step size a type/mode ran this generation with.
+func RecordSigma(gen int, numType, mode string, sigma float64) error {
+	return updateManifest(gen, func(m *GenerationManifest) {
+		if m.Sigma == nil {
+			m.Sigma = map[string]float64{}
+		}
+		m.Sigma[numType+"_"+mode] = sigma
+	})
+}
+
+// RecordCurriculumStage stores the curriculum stage a type/mode is
+// evaluated at this generation.
+func RecordCurriculumStage(gen int, numType, mode string, stage int) error {
+	return updateManifest(gen, func(m *GenerationManifest) {
+		if m.Curriculum == nil {
+			m.Curriculum = map[string]int{}
+		}
+		m.Curriculum[numType+"_"+mode] = stage
+	})
+}
@@ -13,12 +13,20 @@ type TypedMessage struct {
 
 // Outbound message types
 const (
-	TypeStatusUpdate      = "status_update"
-	TypeExperimentConf    = "experiment_config"
-	TypeExperimentDone    = "experiment_done"
-	TypeStatusDelta       = "status_delta"
-	TypeExperimentRunning = "running_update"
-	TypeScoresOverview    = "scores_overview"
+	TypeStatusUpdate        = "status_update"
+	TypeExperimentConf      = "experiment_config"
+	TypeExperimentDone      = "experiment_done"
+	TypeStatusDelta         = "status_delta"
+	TypeExperimentRunning   = "running_update"
+	TypeScoresOverview      = "scores_overview"
+	TypeArchiveOverview     = "archive_overview"
+	TypeGeneralizationCurve = "generalization_curve"
+	TypeControlAck          = "control_ack"
+)
+
+// Inbound message types
+const (
+	TypeExperimentControl = "experiment_control"
 )
 
 // SerializeTyped returns a JSON-encoded message of {type, data}
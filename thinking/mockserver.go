@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// mockServer speaks enough of the Primordia TCP protocol for construct,
+// discover and the Primordia backend: a password first, then JSON
+// commands, every message terminated by the delimiter. Commands the real
+// server does not answer (spawn, despawn, freeze, forces) get no reply here
+// either, so a stray reply never lands in a cube's position read.
+type mockServer struct {
+	cfg      MockServerConfig
+	password string
+	world    *LocalWorld
+	planets  []SimPlanet
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// errMockDrop closes the connection as an injected failure.
+var errMockDrop = errors.New("injected connection failure")
+
+func newMockServer(cfg *ExperimentConfig) (*mockServer, error) {
+	world := NewLocalWorld(cfg)
+	scan, err := world.ScanPlanets()
+	if err != nil {
+		return nil, err
+	}
+	seed := cfg.MockServer.Seed
+	if seed == 0 {
+		seed = cfg.Seed
+	}
+	password := cfg.MockServer.Password
+	if password == "" {
+		password = primordiaAuthPass
+	}
+	return &mockServer{
+		cfg:      cfg.MockServer,
+		password: password,
+		world:    world,
+		planets:  scan.Planets,
+		rng:      rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// runMockServer is the "mockserver" subcommand. It serves until interrupted.
+func runMockServer(args []string) error {
+	fs := flag.NewFlagSet("mockserver", flag.ContinueOnError)
+	configPath := fs.String("config", "experiment_config.json", "experiment config with planets, local_sim and mock_server")
+	addr := fs.String("addr", "", "listen address (overrides mock_server.addr)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := LoadExperimentConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if *addr != "" {
+		cfg.MockServer.Addr = *addr
+	}
+	if cfg.MockServer.Addr == "" {
+		cfg.MockServer.Addr = fmt.Sprintf(":%d", primordiaPort)
+	}
+
+	srv, err := newMockServer(cfg)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.Serve(ctx, cfg.MockServer.Addr)
+}
+
+// Serve accepts connections on addr until ctx is cancelled.
+func (s *mockServer) Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.serveListener(ctx, ln)
+}
+
+// serveListener is Serve on a listener the caller opened, so tests can
+// bind 127.0.0.1:0 and read the port back.
+func (s *mockServer) serveListener(ctx context.Context, ln net.Listener) error {
+	fmt.Printf("🧪 Mock Primordia listening on %s (%d planets, latency %dms±%dms, fail %.2f, drop %.2f)\n",
+		ln.Addr(), len(s.planets), s.cfg.LatencyMs, s.cfg.JitterMs, s.cfg.FailRate, s.cfg.DropRate)
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Println("🛑 Mock Primordia stopped")
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle runs one client connection. The cube a connection spawned is the
+// one its get_cube_state queries and forces refer to, as on the server.
+func (s *mockServer) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	scanner.Split(splitDelimited(primordiaDelimiter))
+
+	if !scanner.Scan() {
+		return
+	}
+	if string(bytes.TrimSpace(scanner.Bytes())) != s.password {
+		s.reply(conn, map[string]any{"type": "auth_failed"})
+		return
+	}
+	if err := s.reply(conn, map[string]any{"type": "auth_success"}); err != nil {
+		return
+	}
+
+	var own string
+	for scanner.Scan() {
+		var cmd map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &cmd); err != nil {
+			s.reply(conn, map[string]any{"type": "error", "error": "bad JSON"})
+			continue
+		}
+		drop, err := s.inject()
+		if err != nil {
+			fmt.Printf("⚠️ Mock Primordia dropping %s after %v\n", conn.RemoteAddr(), cmd["type"])
+			return
+		}
+		if drop {
+			continue
+		}
+		if err := s.dispatch(conn, cmd, &own); err != nil {
+			return
+		}
+	}
+}
+
+func (s *mockServer) dispatch(conn net.Conn, cmd map[string]any, own *string) error {
+	switch cmd["type"] {
+	case "get_cube_list":
+		scan, _ := s.world.ScanPlanets()
+		cubes := make([]string, 0, len(scan.Cubes))
+		for name := range scan.Cubes {
+			cubes = append(cubes, name)
+		}
+		sort.Strings(cubes)
+		return s.reply(conn, map[string]any{"type": "cube_list", "cubes": cubes})
+
+	case "get_planets":
+		planets := make([]map[string]any, 0, len(s.planets))
+		for _, p := range s.planets {
+			planets = append(planets, map[string]any{
+				"Name":     p.Name,
+				"Position": map[string]float64{"x": p.Center[0], "y": p.Center[1], "z": p.Center[2]},
+			})
+		}
+		return s.reply(conn, map[string][]map[string]any{"planets": planets})
+
+	case "spawn_cube":
+		name, _ := cmd["cube_name"].(string)
+		pos := floats(cmd["position"])
+		if name == "" || len(pos) != 3 {
+			return nil
+		}
+		// Every cube is spawned as a base and suffixed, as the clients ask.
+		spawned, err := s.world.SpawnCube(name, pos)
+		if err == nil {
+			*own = spawned
+		}
+
+	case "despawn_cube":
+		name, _ := cmd["cube_name"].(string)
+		_ = s.world.DespawnCube(name)
+		if name == *own {
+			*own = ""
+		}
+
+	case "freeze_cube":
+		name, _ := cmd["cube_name"].(string)
+		frozen, _ := cmd["freeze"].(bool)
+		_ = s.world.SetFrozen(name, frozen)
+
+	case "apply_force":
+		if force := floats(cmd["force"]); *own != "" && len(force) == 3 {
+			_ = s.world.ApplyForce(*own, force)
+		}
+
+	case "get_cube_state":
+		pos, err := s.world.Position(*own)
+		if err != nil {
+			return s.reply(conn, map[string]any{"type": "error", "error": "no cube on this connection"})
+		}
+		return s.reply(conn, map[string]any{"type": "cube_state", "name": *own, "position": pos})
+
+	default:
+		return s.reply(conn, map[string]any{"type": "error", "error": fmt.Sprintf("unknown command %v", cmd["type"])})
+	}
+	return nil
+}
+
+// inject waits out the configured latency, then draws whether this command
+// kills the connection (an error) or is silently ignored (drop).
+func (s *mockServer) inject() (drop bool, err error) {
+	s.rngMu.Lock()
+	delay := time.Duration(s.cfg.LatencyMs) * time.Millisecond
+	if s.cfg.JitterMs > 0 {
+		delay += time.Duration(s.rng.Intn(s.cfg.JitterMs+1)) * time.Millisecond
+	}
+	fail := s.cfg.FailRate > 0 && s.rng.Float64() < s.cfg.FailRate
+	drop = s.cfg.DropRate > 0 && s.rng.Float64() < s.cfg.DropRate
+	s.rngMu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if fail {
+		return false, errMockDrop
+	}
+	return drop, nil
+}
+
+func (s *mockServer) reply(conn net.Conn, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, primordiaDelimiter...))
+	return err
+}
+
+// splitDelimited splits a stream into delimiter-terminated messages.
+func splitDelimited(delimiter string) bufio.SplitFunc {
+	delim := []byte(delimiter)
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, delim); i >= 0 {
+			return i + len(delim), data[:i], nil
+		}
+		if atEOF && len(bytes.TrimSpace(data)) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// floats reads a JSON number array.
+func floats(v any) []float64 {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]float64, 0, len(arr))
+	for _, el := range arr {
+		f, ok := el.(float64)
+		if !ok {
+			return nil
+		}
+		out = append(out, f)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// startMockServer serves a one-planet mock Primordia on a free local port
+// and returns a Primordia backend pointed at it.
+func startMockServer(t *testing.T, mock MockServerConfig) *primordiaBackend {
+	t.Helper()
+	cfg := &ExperimentConfig{Planets: []string{"(0,0,0)"}, Seed: 1, MockServer: mock}
+	srv, err := newMockServer(cfg)
+	if err != nil {
+		t.Fatalf("newMockServer: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.serveListener(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return newPrimordiaBackendAt(addr.IP.String(), addr.Port)
+}
+
+func TestMockServerDrivesPrimordiaBackend(t *testing.T) {
+	p := startMockServer(t, MockServerConfig{})
+
+	scan, err := p.ScanPlanets()
+	if err != nil {
+		t.Fatalf("ScanPlanets: %v", err)
+	}
+	if len(scan.Planets) != 1 {
+		t.Fatalf("got %d planets, want 1", len(scan.Planets))
+	}
+
+	name, err := p.SpawnCube("probe", []float64{0, 500, 0})
+	if err != nil {
+		t.Fatalf("SpawnCube: %v", err)
+	}
+	if name != "probe_BASE" {
+		t.Fatalf("spawned %q, want probe_BASE", name)
+	}
+	start, err := p.Position(name)
+	if err != nil {
+		t.Fatalf("Position: %v", err)
+	}
+
+	// a frozen cube ignores forces
+	if err := p.ApplyForce(name, []float64{0, 0, 0}); err != nil {
+		t.Fatalf("ApplyForce: %v", err)
+	}
+	if pos, err := p.Position(name); err != nil || pos[1] != start[1] {
+		t.Fatalf("frozen cube moved: %v (%v), started at %v", pos, err, start)
+	}
+
+	if err := p.UnfreezeAll(); err != nil {
+		t.Fatalf("UnfreezeAll: %v", err)
+	}
+	if err := p.ApplyForce(name, []float64{0, 0, 0}); err != nil {
+		t.Fatalf("ApplyForce: %v", err)
+	}
+	if pos, err := p.Position(name); err != nil || pos[1] == start[1] {
+		t.Fatalf("unfrozen cube did not fall: %v (%v), started at %v", pos, err, start)
+	}
+
+	if err := p.DestroyAll(); err != nil {
+		t.Fatalf("DestroyAll: %v", err)
+	}
+	scan, err = p.ScanPlanets()
+	if err != nil {
+		t.Fatalf("ScanPlanets after DestroyAll: %v", err)
+	}
+	if len(scan.Cubes) != 0 {
+		t.Fatalf("%d cube(s) left after DestroyAll", len(scan.Cubes))
+	}
+}
+
+func TestMockServerFailureIsPodFault(t *testing.T) {
+	p := startMockServer(t, MockServerConfig{FailRate: 1})
+
+	err := p.UnfreezeAll()
+	if err == nil {
+		t.Fatal("UnfreezeAll succeeded with fail_rate 1")
+	}
+	if !isPodFault(err) {
+		t.Fatalf("isPodFault(%v) = false, want true", err)
+	}
+}
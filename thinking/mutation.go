@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// integerNoiseScale widens noise for integer networks the same way
+// paragon's PerturbWeights does, so stddevs mean the same thing as before.
+const integerNoiseScale = 10
+
+// defaultSparseFraction is the share of targeted parameters touched by
+// sparse noise when sparse_fraction is unset.
+const defaultSparseFraction = 0.1
+
+// noiseSampler draws one unit-scale noise value.
+type noiseSampler func(rng *rand.Rand) float64
+
+var noiseSamplers = map[string]noiseSampler{
+	"gaussian": func(rng *rand.Rand) float64 { return rng.NormFloat64() },
+	"uniform":  func(rng *rand.Rand) float64 { return rng.Float64()*2 - 1 },
+	"cauchy":   func(rng *rand.Rand) float64 { return math.Tan(math.Pi * (rng.Float64() - 0.5)) },
+	// sparse draws gaussian noise, but only for sparse_fraction of the targets
+	"sparse": func(rng *rand.Rand) float64 { return rng.NormFloat64() },
+}
+
+// noiseFor resolves noise_type and the fraction of parameters it touches.
+func noiseFor(ms MutationStrategy) (noiseSampler, float64, error) {
+	kind := strings.ToLower(strings.TrimSpace(ms.NoiseType))
+	if kind == "" {
+		kind = "gaussian"
+	}
+	sample, ok := noiseSamplers[kind]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown noise_type %q", ms.NoiseType)
+	}
+	fraction := 1.0
+	if kind == "sparse" {
+		fraction = ms.SparseFraction
+		if fraction <= 0 || fraction > 1 {
+			fraction = defaultSparseFraction
+		}
+	}
+	return sample, fraction, nil
+}
+
+// mutationTargets filters the layout down to what apply_noise_to selects:
+// weights (default), biases, both/all, or one layer as "layer:N" or "N".
+func mutationTargets(applyTo string, refs []paramRef, numLayers int) ([]paramRef, error) {
+	target := strings.ToLower(strings.TrimSpace(applyTo))
+	var keep func(r paramRef) bool
+
+	switch target {
+	case "", "weights":
+		keep = func(r paramRef) bool { return !r.IsBias() }
+	case "biases", "bias":
+		keep = func(r paramRef) bool { return r.IsBias() }
+	case "both", "all":
+		keep = func(r paramRef) bool { return true }
+	default:
+		layer, err := strconv.Atoi(strings.TrimPrefix(target, "layer:"))
+		if err != nil {
+			return nil, fmt.Errorf("unknown apply_noise_to %q", applyTo)
+		}
+		if layer < 1 || layer >= numLayers {
+			return nil, fmt.Errorf("apply_noise_to layer %d out of range (1..%d)", layer, numLayers-1)
+		}
+		keep = func(r paramRef) bool { return r.Layer == layer }
+	}
+
+	out := make([]paramRef, 0, len(refs))
+	for _, r := range refs {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// mutateNetwork adds noise scaled by stddev to the parameters selected by
+// the mutation strategy. Integer networks are rounded and clamped to their
+// type's range instead of wrapping around.
+func mutateNetwork[T paragon.Numeric](net *paragon.Network[T], ms MutationStrategy, stddev float64, rng *rand.Rand) error {
+	sample, fraction, err := noiseFor(ms)
+	if err != nil {
+		return err
+	}
+	refs, err := mutationTargets(ms.ApplyNoiseTo, paramLayout(net), len(net.Layers))
+	if err != nil {
+		return err
+	}
+
+	if _, _, integer := numericRange[T](); integer {
+		stddev *= integerNoiseScale
+	}
+
+	vals := getParams(net, refs)
+	for i := range vals {
+		if fraction < 1 && rng.Float64() >= fraction {
+			continue
+		}
+		vals[i] += sample(rng) * stddev
+	}
+	setParams(net, refs, vals)
+	return nil
+}
+
+// reinitNetwork draws fresh parameters using paragon's initial ranges
+// (see initialWeight) and zero biases. Unlike paragon it uses rng so
+// results are reproducible.
+func reinitNetwork[T paragon.Numeric](net *paragon.Network[T], rng *rand.Rand) {
+	for l := 1; l < len(net.Layers); l++ {
+		layer := net.Layers[l]
+		for y := 0; y < layer.Height; y++ {
+			for x := 0; x < layer.Width; x++ {
+				n := layer.Neurons[y][x]
+				n.Bias = 0
+				for k := range n.Inputs {
+					n.Inputs[k].Weight = initialWeight[T](rng, len(n.Inputs))
+				}
+			}
+		}
+	}
+}
+
+// deriveVariant is the single path from a parent to a variant, so any
+// variant can be rebuilt from its manifest entry: same parent, strategy,
+// stddev and seed give the same network. It also returns the structural
+// change applied, if any.
+func deriveVariant[T paragon.Numeric](parent *paragon.Network[T], ms MutationStrategy, stddev float64, seed int64) (*paragon.Network[T], string, error) {
+	clone, err := cloneNetwork(parent)
+	if err != nil {
+		return nil, "", err
+	}
+	rng := rand.New(rand.NewSource(seed))
+	if !ms.ReuseBestModel {
+		reinitNetwork(clone, rng)
+	} else if err := mutateNetwork(clone, ms, stddev, rng); err != nil {
+		return nil, "", err
+	}
+	change, err := mutateStructure(clone, ms.Structural, rng)
+	if err != nil {
+		return nil, "", err
+	}
+	return clone, change, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Objectives every variant summary records. All are oriented so that higher
+// is better except the ones listed in minimizedObjectives.
+const (
+	ObjectiveProgress     = "progress"      // mean configured score (mean_progress)
+	ObjectiveEnergy       = "energy"        // mean per-cube sum of |force| and |torque| sent
+	ObjectiveSurvival     = "survival"      // mean seconds a cube kept answering
+	ObjectiveVerticalGain = "vertical_gain" // mean Δy
+	ObjectiveConsistency  = "consistency"   // minus the stddev of per-planet mean scores
+)
+
+var allObjectives = []string{ObjectiveProgress, ObjectiveEnergy, ObjectiveSurvival, ObjectiveVerticalGain, ObjectiveConsistency}
+
+var minimizedObjectives = map[string]bool{ObjectiveEnergy: true}
+
+// Selection methods selectable with objectives.selection
+const (
+	SelectionScalar = "scalar"
+	SelectionNSGA2  = "nsga2"
+)
+
+func (o ObjectivesConfig) SelectionName() string {
+	if o.Selection == "" {
+		return SelectionScalar
+	}
+	return strings.ToLower(o.Selection)
+}
+
+// ObjectiveNames is what nsga2 ranks on, defaulting to every objective.
+func (o ObjectivesConfig) ObjectiveNames() []string {
+	if len(o.Use) == 0 {
+		return allObjectives
+	}
+	return o.Use
+}
+
+// objectiveSample is what the objectives see of one evaluated cube.
+type objectiveSample struct {
+	Planet   string
+	Score    float64
+	Energy   float64
+	Survival float64
+	DeltaY   float64
+}
+
+func variantObjectives(samples []objectiveSample) map[string]float64 {
+	var scores, energy, survival, deltaY []float64
+	byPlanet := map[string][]float64{}
+	for _, s := range samples {
+		scores = append(scores, s.Score)
+		energy = append(energy, s.Energy)
+		survival = append(survival, s.Survival)
+		deltaY = append(deltaY, s.DeltaY)
+		byPlanet[s.Planet] = append(byPlanet[s.Planet], s.Score)
+	}
+
+	var planetMeans []float64
+	for _, ps := range byPlanet {
+		planetMeans = append(planetMeans, Mean(ps))
+	}
+	spread := 0.0
+	if len(planetMeans) > 1 {
+		m := Mean(planetMeans)
+		for _, v := range planetMeans {
+			spread += (v - m) * (v - m)
+		}
+		spread = math.Sqrt(spread / float64(len(planetMeans)))
+	}
+
+	return map[string]float64{
+		ObjectiveProgress:     Mean(scores),
+		ObjectiveEnergy:       Mean(energy),
+		ObjectiveSurvival:     Mean(survival),
+		ObjectiveVerticalGain: Mean(deltaY),
+		ObjectiveConsistency:  -spread,
+	}
+}
+
+// dominates reports whether a is at least as good as b on every objective
+// and strictly better on one; both are already oriented for maximization.
+func dominates(a, b []float64) bool {
+	better := false
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+		if a[i] > b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// paretoFronts is NSGA-II's fast non-dominated sort: fronts[0] holds the
+// indices nobody dominates, fronts[1] those only dominated by fronts[0]…
+func paretoFronts(points [][]float64) [][]int {
+	n := len(points)
+	dominatedBy := make([]int, n)
+	dominating := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			switch {
+			case dominates(points[i], points[j]):
+				dominating[i] = append(dominating[i], j)
+				dominatedBy[j]++
+			case dominates(points[j], points[i]):
+				dominating[j] = append(dominating[j], i)
+				dominatedBy[i]++
+			}
+		}
+	}
+
+	var fronts [][]int
+	var current []int
+	for i := 0; i < n; i++ {
+		if dominatedBy[i] == 0 {
+			current = append(current, i)
+		}
+	}
+	for len(current) > 0 {
+		fronts = append(fronts, current)
+		var next []int
+		for _, i := range current {
+			for _, j := range dominating[i] {
+				if dominatedBy[j]--; dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		current = next
+	}
+	return fronts
+}
+
+// crowdingDistances sums, per objective, the normalized gap between each
+// member's neighbours on the front. Boundary members get len(objectives)+1,
+// more than any interior member can reach, so they are always kept.
+func crowdingDistances(points [][]float64, front []int) map[int]float64 {
+	dist := make(map[int]float64, len(front))
+	if len(front) == 0 {
+		return dist
+	}
+	boundary := float64(len(points[front[0]]) + 1)
+	for i := range front {
+		dist[front[i]] = 0
+	}
+	for k := range points[front[0]] {
+		order := append([]int(nil), front...)
+		sort.SliceStable(order, func(a, b int) bool { return points[order[a]][k] < points[order[b]][k] })
+		lo, hi := points[order[0]][k], points[order[len(order)-1]][k]
+		dist[order[0]], dist[order[len(order)-1]] = boundary, boundary
+		if hi == lo {
+			continue
+		}
+		for i := 1; i < len(order)-1; i++ {
+			if dist[order[i]] < boundary {
+				dist[order[i]] += (points[order[i+1]][k] - points[order[i-1]][k]) / (hi - lo)
+			}
+		}
+	}
+	return dist
+}
+
+// rankNSGA2 orders results by front, then by crowding distance, and sets
+// Front (1 is non-dominated), Crowding and a Selection score that keeps
+// that order for parent selection.
+func rankNSGA2(results []rankedResult, names []string) error {
+	points := make([][]float64, len(results))
+	for i, r := range results {
+		points[i] = make([]float64, len(names))
+		for k, name := range names {
+			v, ok := r.Objectives[name]
+			if !ok {
+				return fmt.Errorf("variant %s has no %q objective", r.Variant, name)
+			}
+			if minimizedObjectives[name] {
+				v = -v
+			}
+			points[i][k] = v
+		}
+	}
+
+	for f, front := range paretoFronts(points) {
+		crowding := crowdingDistances(points, front)
+		for _, i := range front {
+			c := crowding[i]
+			results[i].Front = f + 1
+			results[i].Crowding = c
+			selection := -float64(f) + 0.5*c/(1+c)
+			results[i].Selection = &selection
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Front != results[j].Front {
+			return results[i].Front < results[j].Front
+		}
+		if results[i].Crowding != results[j].Crowding {
+			return results[i].Crowding > results[j].Crowding
+		}
+		return results[i].MeanProgress > results[j].MeanProgress
+	})
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Observation features selectable with observation.features, fed to the
+// network's input layer in the order listed.
+const (
+	FeaturePosition        = "position"         // raw world position (x, y, z)
+	FeatureRelativeGoal    = "relative_goal"    // goal − position (x, y, z)
+	FeatureGoalDistance    = "goal_distance"    // |goal − position|
+	FeatureVelocity        = "velocity"         // position change per second since the last pulse (x, y, z)
+	FeaturePlanetDirection = "planet_direction" // unit vector towards the planet center (x, y, z)
+	FeatureTimeRemaining   = "time_remaining"   // seconds of lifespan left
+)
+
+var featureWidths = map[string]int{
+	FeaturePosition:        3,
+	FeatureRelativeGoal:    3,
+	FeatureGoalDistance:    1,
+	FeatureVelocity:        3,
+	FeaturePlanetDirection: 3,
+	FeatureTimeRemaining:   1,
+}
+
+// Normalizations selectable with observation.normalize
+const (
+	NormalizeNone  = "none"  // raw world units and seconds
+	NormalizeScale = "scale" // distances / distance_scale, velocity / velocity_scale, time as a 0–1 fraction
+	NormalizeTanh  = "tanh"  // scale, then squashed into (-1, 1)
+)
+
+const (
+	defaultDistanceScale = 100.0
+	defaultVelocityScale = 10.0
+)
+
+// FeatureNames defaults to the raw position, the input the networks were
+// originally fed.
+func (o ObservationConfig) FeatureNames() []string {
+	if len(o.Features) == 0 {
+		return []string{FeaturePosition}
+	}
+	return o.Features
+}
+
+func (o ObservationConfig) NormalizeName() string {
+	if o.Normalize == "" {
+		return NormalizeNone
+	}
+	return strings.ToLower(o.Normalize)
+}
+
+// Width is the number of inputs the features add up to.
+func (o ObservationConfig) Width() (int, error) {
+	width := 0
+	for _, name := range o.FeatureNames() {
+		w, ok := featureWidths[strings.ToLower(name)]
+		if !ok {
+			return 0, fmt.Errorf("unknown observation feature %q", name)
+		}
+		width += w
+	}
+	return width, nil
+}
+
+// Validate checks the features and normalization and that they fill the
+// input layer of network_config exactly.
+func (o ObservationConfig) Validate(net NetworkConfig) error {
+	width, err := o.Width()
+	if err != nil {
+		return err
+	}
+	switch o.NormalizeName() {
+	case NormalizeNone, NormalizeScale, NormalizeTanh:
+	default:
+		return fmt.Errorf("unknown observation normalize %q", o.Normalize)
+	}
+	if len(net.Layers) == 0 {
+		return nil
+	}
+	in := net.Layers[0]
+	if inputs := in.Width * in.Height; inputs != width {
+		return fmt.Errorf("observation features %v give %d inputs but network_config.layers[0] is %dx%d (%d)",
+			o.FeatureNames(), width, in.Width, in.Height, inputs)
+	}
+	return nil
+}
+
+// observer builds one cube's observation at every pulse. It keeps the
+// previous position to estimate velocity.
+type observer struct {
+	spec     ObservationConfig
+	lifespan time.Duration
+	prev     []float64
+	prevAt   time.Duration
+}
+
+func newObserver(spec ObservationConfig, lifespan time.Duration) *observer {
+	return &observer{spec: spec, lifespan: lifespan}
+}
+
+// Observe assembles the feature vector for a cube at pos, elapsed into its
+// lifespan.
+func (o *observer) Observe(pos, goal, center []float64, elapsed time.Duration) []float64 {
+	norm := o.spec.NormalizeName()
+	distScale, velScale := o.spec.DistanceScale, o.spec.VelocityScale
+	if distScale <= 0 {
+		distScale = defaultDistanceScale
+	}
+	if velScale <= 0 {
+		velScale = defaultVelocityScale
+	}
+	scaled := func(v, scale float64) float64 {
+		switch norm {
+		case NormalizeScale:
+			return v / scale
+		case NormalizeTanh:
+			return math.Tanh(v / scale)
+		default:
+			return v
+		}
+	}
+
+	var obs []float64
+	for _, name := range o.spec.FeatureNames() {
+		switch strings.ToLower(name) {
+		case FeaturePosition:
+			for k := 0; k < 3; k++ {
+				obs = append(obs, scaled(pos[k], distScale))
+			}
+		case FeatureRelativeGoal:
+			for k := 0; k < 3; k++ {
+				obs = append(obs, scaled(goal[k]-pos[k], distScale))
+			}
+		case FeatureGoalDistance:
+			obs = append(obs, scaled(distance(goal, pos), distScale))
+		case FeatureVelocity:
+			vel := make([]float64, 3)
+			if dt := (elapsed - o.prevAt).Seconds(); o.prev != nil && dt > 0 {
+				for k := range vel {
+					vel[k] = (pos[k] - o.prev[k]) / dt
+				}
+			}
+			for _, v := range vel {
+				obs = append(obs, scaled(v, velScale))
+			}
+		case FeaturePlanetDirection:
+			d := distance(center, pos)
+			for k := 0; k < 3; k++ {
+				if d > 0 {
+					obs = append(obs, (center[k]-pos[k])/d)
+				} else {
+					obs = append(obs, 0)
+				}
+			}
+		case FeatureTimeRemaining:
+			left := math.Max(0, (o.lifespan - elapsed).Seconds())
+			if norm != NormalizeNone && o.lifespan > 0 {
+				left /= o.lifespan.Seconds()
+			}
+			obs = append(obs, left)
+		}
+	}
+
+	o.prev, o.prevAt = append(o.prev[:0], pos...), elapsed
+	return obs
+}
+
+// shapeInput lays a flat observation out as the input grid's rows.
+func shapeInput(obs []float64, width, height int) ([][]float64, error) {
+	if len(obs) != width*height {
+		return nil, fmt.Errorf("observation has %d values, input layer is %dx%d", len(obs), width, height)
+	}
+	rows := make([][]float64, height)
+	for y := range rows {
+		rows[y] = obs[y*width : (y+1)*width]
+	}
+	return rows, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"reflect"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// paramRef addresses one trainable value in a network: a connection weight,
+// or the neuron's bias when Input is -1. Layer 0 (inputs) has neither.
+type paramRef struct {
+	Layer, Y, X int
+	Input       int
+}
+
+func (r paramRef) IsBias() bool { return r.Input < 0 }
+
+// paramLayout lists every weight and bias in a fixed order: layer by layer,
+// neuron by neuron, bias first then incoming weights.
+func paramLayout[T paragon.Numeric](net *paragon.Network[T]) []paramRef {
+	var refs []paramRef
+	for l := 1; l < len(net.Layers); l++ {
+		layer := net.Layers[l]
+		for y := 0; y < layer.Height; y++ {
+			for x := 0; x < layer.Width; x++ {
+				refs = append(refs, paramRef{l, y, x, -1})
+				for k := range layer.Neurons[y][x].Inputs {
+					refs = append(refs, paramRef{l, y, x, k})
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// getParams reads the referenced values as float64.
+func getParams[T paragon.Numeric](net *paragon.Network[T], refs []paramRef) []float64 {
+	vals := make([]float64, len(refs))
+	for i, r := range refs {
+		n := net.Layers[r.Layer].Neurons[r.Y][r.X]
+		if r.IsBias() {
+			vals[i] = float64(n.Bias)
+		} else {
+			vals[i] = float64(n.Inputs[r.Input].Weight)
+		}
+	}
+	return vals
+}
+
+// setParams writes vals back, rounding and clamping for integer types.
+func setParams[T paragon.Numeric](net *paragon.Network[T], refs []paramRef, vals []float64) {
+	for i, r := range refs {
+		n := net.Layers[r.Layer].Neurons[r.Y][r.X]
+		if r.IsBias() {
+			n.Bias = toNumeric[T](vals[i])
+		} else {
+			n.Inputs[r.Input].Weight = toNumeric[T](vals[i])
+		}
+	}
+}
+
+// numericRange is the representable range of T and whether T is an integer.
+func numericRange[T paragon.Numeric]() (lo, hi float64, integer bool) {
+	switch reflect.TypeOf(*new(T)).Kind() {
+	case reflect.Int8:
+		return math.MinInt8, math.MaxInt8, true
+	case reflect.Int16:
+		return math.MinInt16, math.MaxInt16, true
+	case reflect.Int32:
+		return math.MinInt32, math.MaxInt32, true
+	case reflect.Int, reflect.Int64:
+		// float64(MaxInt64) rounds up past the range, so step just inside it
+		return math.MinInt64, math.Nextafter(math.MaxInt64, 0), true
+	case reflect.Uint8:
+		return 0, math.MaxUint8, true
+	case reflect.Uint16:
+		return 0, math.MaxUint16, true
+	case reflect.Uint32:
+		return 0, math.MaxUint32, true
+	case reflect.Uint, reflect.Uint64:
+		return 0, math.Nextafter(math.MaxUint64, 0), true
+	case reflect.Float32:
+		return -math.MaxFloat32, math.MaxFloat32, false
+	default:
+		return -math.MaxFloat64, math.MaxFloat64, false
+	}
+}
+
+// toNumeric converts v to T without wrapping around on overflow.
+func toNumeric[T paragon.Numeric](v float64) T {
+	lo, hi, integer := numericRange[T]()
+	if math.IsNaN(v) {
+		return 0
+	}
+	if integer {
+		v = math.Round(v)
+	}
+	if v < lo {
+		v = lo
+	}
+	if v > hi {
+		v = hi
+	}
+	return T(v)
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+const (
+	defaultTournamentSize    = 3
+	defaultSelectionPressure = 1.5
+)
+
+// PopulationMember is one variant slot of a generation's population.
+type PopulationMember struct {
+	Variant   int      `json:"variant"`
+	Origin    string   `json:"origin"` // "elite", "offspring", "crossover" or "initial"
+	Parent    string   `json:"parent"` // model file the member was derived from
+	CoParent  string   `json:"co_parent,omitempty"`
+	Crossover string   `json:"crossover,omitempty"` // operator that bred it, if any
+	Fitness   float64  `json:"fitness"`
+	Selection *float64 `json:"selection,omitempty"` // novelty score when diversity.mode is novelty
+	Evaluated bool     `json:"evaluated"`
+}
+
+// Population is stored at models/<gen>/population/<type>_<mode>.json.
+// GenerateVariants writes it, AggregateVariantResults fills in fitness and
+// the next generation selects its parents from it.
+type Population struct {
+	Generation int                `json:"generation"`
+	NumType    string             `json:"num_type"`
+	Mode       string             `json:"mode"`
+	Members    []PopulationMember `json:"members"`
+}
+
+func populationPath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "population", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+func variantPath(gen int, numType, mode string, variant int) string {
+	return filepath.Join("models", strconv.Itoa(gen), fmt.Sprintf("mutated_%s_%s", numType, mode),
+		fmt.Sprintf("variant_%d.json", variant))
+}
+
+func LoadPopulation(gen int, numType, mode string) (*Population, error) {
+	data, err := os.ReadFile(populationPath(gen, numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var pop Population
+	if err := json.Unmarshal(data, &pop); err != nil {
+		return nil, err
+	}
+	return &pop, nil
+}
+
+func SavePopulation(pop *Population) error {
+	path := populationPath(pop.Generation, pop.NumType, pop.Mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pop, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// rankedParent is a candidate parent, best first.
+type rankedParent struct {
+	Path  string
+	Score float64 // selection score: fitness, or novelty under diversity.mode novelty
+}
+
+// rankedParents returns last generation's evaluated members, best first.
+// Runs that switched optimizer mid-way fall back to total_results. In
+// map_elites mode the parents are the archive's elites instead.
+func rankedParents(cfg *ExperimentConfig, gen int, numType, mode string) ([]rankedParent, error) {
+	if cfg.Diversity.ModeName() == DiversityMapElites {
+		if parents, err := mapElitesParents(numType, mode); err == nil && len(parents) > 0 {
+			return parents, nil
+		}
+	}
+
+	var parents []rankedParent
+	if pop, err := LoadPopulation(gen, numType, mode); err == nil {
+		for _, m := range pop.Members {
+			if m.Evaluated {
+				score := m.Fitness
+				if m.Selection != nil {
+					score = *m.Selection
+				}
+				parents = append(parents, rankedParent{variantPath(gen, numType, mode, m.Variant), score})
+			}
+		}
+	} else {
+		ranked, err := loadRankedResults(gen, numType, mode)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range ranked {
+			v, err := strconv.Atoi(r.Variant)
+			if err != nil {
+				continue
+			}
+			parents = append(parents, rankedParent{variantPath(gen, numType, mode, v), r.SelectionScore()})
+		}
+	}
+	if len(parents) == 0 {
+		return nil, fmt.Errorf("no evaluated members in generation %d for %s_%s", gen, numType, mode)
+	}
+	sort.SliceStable(parents, func(i, j int) bool { return parents[i].Score > parents[j].Score })
+	return parents, nil
+}
+
+// rankedResult is one entry of total_results, ordered by mean_progress or,
+// with objectives.selection nsga2, by Pareto front and crowding. Selection
+// is set when parents are ranked by something other than mean_progress.
+type rankedResult struct {
+	Variant      string             `json:"variant"`
+	MeanProgress float64            `json:"mean_progress"`
+	Objectives   map[string]float64 `json:"objectives,omitempty"`
+	Front        int                `json:"front,omitempty"` // nsga2: 1 is the non-dominated front
+	Crowding     float64            `json:"crowding,omitempty"`
+	Selection    *float64           `json:"selection,omitempty"`
+}
+
+// SelectionScore is what parent selection ranks by.
+func (r rankedResult) SelectionScore() float64 {
+	if r.Selection != nil {
+		return *r.Selection
+	}
+	return r.MeanProgress
+}
+
+// fittestResult is the entry with the highest mean_progress, whatever order
+// total_results is in.
+func fittestResult(ranked []rankedResult) rankedResult {
+	best := ranked[0]
+	for _, r := range ranked[1:] {
+		if r.MeanProgress > best.MeanProgress {
+			best = r
+		}
+	}
+	return best
+}
+
+func loadRankedResults(gen int, numType, mode string) ([]rankedResult, error) {
+	path := filepath.Join("models", strconv.Itoa(gen), "total_results", fmt.Sprintf("%s_%s.json", numType, mode))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ranked []rankedResult
+	if err := json.Unmarshal(data, &ranked); err != nil {
+		return nil, err
+	}
+	return ranked, nil
+}
+
+// selectParent picks an index into parents (sorted best first).
+func selectParent(pc PopulationConfig, n int, rng *rand.Rand) int {
+	if strings.ToLower(pc.Selection) == "rank" {
+		return rankSelect(n, pc.SelectionPressure, rng)
+	}
+	size := pc.TournamentSize
+	if size <= 0 {
+		size = defaultTournamentSize
+	}
+	best := rng.Intn(n)
+	for k := 1; k < size; k++ {
+		if c := rng.Intn(n); c < best {
+			best = c
+		}
+	}
+	return best
+}
+
+// rankSelect uses linear ranking: the best of n gets pressure/n of the mass,
+// the worst (2-pressure)/n.
+func rankSelect(n int, pressure float64, rng *rand.Rand) int {
+	if pressure < 1 || pressure > 2 {
+		pressure = defaultSelectionPressure
+	}
+	if n == 1 {
+		return 0
+	}
+	r := rng.Float64()
+	acc := 0.0
+	for i := 0; i < n; i++ {
+		acc += (pressure - (2*pressure-2)*float64(i)/float64(n-1)) / float64(n)
+		if r < acc {
+			return i
+		}
+	}
+	return n - 1
+}
+
+// generatePopulation builds this generation's population: elites are
+// copied unchanged, the rest are offspring of selected parents (bred by
+// crossover with probability crossover.rate). Selection
+// always consumes the same random draws, so resuming a half-written
+// generation reproduces the same members.
+func (e *Experiment[T, M]) generatePopulation(ctx context.Context) {
+	mode := e.Mode.String()
+	pc := e.Config.Population
+	size := e.Config.VariantCount()
+
+	var parents []rankedParent
+	if e.Gen == 0 {
+		parents = []rankedParent{{Path: filepath.Join("models", "0", fmt.Sprintf("%s_%s.json", e.NumType, mode))}}
+	} else {
+		var err error
+		if parents, err = rankedParents(e.Config, e.Gen-1, e.NumType, mode); err != nil {
+			fmt.Printf("❌ Could not load parents for %s_%s: %v\n", e.NumType, mode, err)
+			return
+		}
+	}
+
+	elites := 0
+	if e.Gen > 0 {
+		elites = min(pc.EliteCount, len(parents), size)
+	}
+
+	// offspring always mutate after the first generation; reuse_best_model
+	// only decides whether generation 0 starts fresh
+	ms := e.Config.MutationStrategy
+	if e.Gen > 0 {
+		ms.ReuseBestModel = true
+	}
+
+	if err := os.MkdirAll(filepath.Dir(variantPath(e.Gen, e.NumType, mode, 0)), 0755); err != nil {
+		fmt.Printf("❌ Could not create variant folder: %v\n", err)
+		return
+	}
+
+	sigma, err := e.generationSigma(parents[0].Path)
+	if err != nil {
+		fmt.Printf("❌ Step size for %s_%s: %v\n", e.NumType, mode, err)
+		return
+	}
+	e.reportSigma(sigma, e.Config.StepSize.AdaptationName())
+
+	// parentSigma is what a parent passes on: its own sigma when
+	// self-adaptive, the generation's otherwise
+	parentSigma := func(path string) float64 {
+		if e.Gen > 0 && e.Config.StepSize.AdaptationName() == StepSizeSelfAdaptive {
+			return inheritedSigma(e.Config, path, e.Gen-1, e.NumType, mode)
+		}
+		return sigma
+	}
+
+	pop := &Population{Generation: e.Gen, NumType: e.NumType, Mode: mode}
+	rng := rand.New(rand.NewSource(variantSeed(e.Config.Seed, e.Gen, e.NumType, mode, -1)))
+	cx := e.Config.Crossover
+
+	loaded := map[string]*paragon.Network[T]{}
+	load := func(path string) (*paragon.Network[T], error) {
+		if net, ok := loaded[path]; ok {
+			return net, nil
+		}
+		net, err := loadNetworkAs[T](path)
+		if err == nil {
+			loaded[path] = net
+		}
+		return net, err
+	}
+
+	for i := 0; i < size; i++ {
+		if ctx.Err() != nil {
+			fmt.Printf("🛑 Population generation cancelled for %s_%s\n", e.NumType, mode)
+			return
+		}
+
+		savePath := variantPath(e.Gen, e.NumType, mode, i)
+		_, statErr := os.Stat(savePath)
+		exists := statErr == nil
+
+		if i < elites {
+			parent := parents[i]
+			pop.Members = append(pop.Members, PopulationMember{Variant: i, Origin: "elite", Parent: parent.Path})
+			if exists {
+				continue
+			}
+			data, err := os.ReadFile(parent.Path)
+			if err == nil {
+				err = writeFileAtomic(savePath, data, 0644)
+			}
+			if err != nil {
+				fmt.Printf("❌ Elite %d copy failed: %v\n", i, err)
+				continue
+			}
+			_ = RecordVariant(e.Gen, e.Config.Seed, VariantRecord{
+				NumType: e.NumType, Mode: mode, Variant: i, Source: "elite", Parent: parent.Path,
+				StdDev: parentSigma(parent.Path),
+			})
+			continue
+		}
+
+		parent := parents[selectParent(pc, len(parents), rng)]
+		member := PopulationMember{Variant: i, Origin: "offspring", Parent: parent.Path}
+		if e.Gen == 0 {
+			member.Origin = "initial"
+		}
+		var mate rankedParent
+		if cx.Enabled && len(parents) > 1 && rng.Float64() < cx.RateOrDefault() {
+			mate = parents[selectParent(pc, len(parents), rng)]
+			for mate.Path == parent.Path {
+				mate = parents[rng.Intn(len(parents))]
+			}
+			member.Origin, member.CoParent, member.Crossover = "crossover", mate.Path, cx.OperatorName()
+		}
+
+		net, err := load(parent.Path)
+		if err != nil {
+			fmt.Printf("❌ Failed to load parent %s: %v\n", parent.Path, err)
+			return
+		}
+		// parents whose structure has diverged cannot be crossed; the child
+		// is a plain offspring instead
+		if member.CoParent != "" {
+			if other, err := load(mate.Path); err != nil || !sameArchitecture(net, other) {
+				member.Origin, member.CoParent, member.Crossover = "offspring", "", ""
+			}
+		}
+		pop.Members = append(pop.Members, member)
+		if exists {
+			continue
+		}
+
+		seed := variantSeed(e.Config.Seed, e.Gen, e.NumType, mode, i)
+		stddev := e.childSigma(parentSigma(parent.Path), seed)
+		var child *paragon.Network[T]
+		var structural string
+		if member.CoParent != "" {
+			var other *paragon.Network[T]
+			if other, err = load(mate.Path); err == nil {
+				child, err = deriveCrossover(net, other, cx, ms, stddev, seed)
+			}
+		} else {
+			child, structural, err = deriveVariant(net, ms, stddev, seed)
+		}
+		if err != nil {
+			fmt.Printf("❌ Breeding failed for variant %d: %v\n", i, err)
+			return
+		}
+		if err := saveNetworkAtomic(child, savePath); err != nil {
+			fmt.Printf("❌ Variant %d failed to save: %v\n", i, err)
+			continue
+		}
+		fmt.Printf("💾 Saved %s %d (parent %s)\n", member.Origin, i, parent.Path)
+
+		if err := RecordVariant(e.Gen, e.Config.Seed, VariantRecord{
+			NumType: e.NumType, Mode: mode, Variant: i, Source: member.Origin,
+			Parent: parent.Path, CoParent: member.CoParent, Operator: member.Crossover,
+			Seed: seed, StdDev: stddev, Strategy: ms,
+			Architecture: architectureOf(child), Structural: structural,
+		}); err != nil {
+			fmt.Printf("⚠️ Could not record variant %d in manifest: %v\n", i, err)
+		}
+	}
+
+	if err := SavePopulation(pop); err != nil {
+		fmt.Printf("❌ Failed to save population: %v\n", err)
+	}
+}
+
+// syncPopulationFitness copies the aggregated scores into the population
+// file and orders the members by fitness, best first.
+func syncPopulationFitness(gen int, numType, mode string) {
+	pop, err := LoadPopulation(gen, numType, mode)
+	if err != nil {
+		return
+	}
+	ranked, err := loadRankedResults(gen, numType, mode)
+	if err != nil {
+		return
+	}
+	scores := make(map[string]rankedResult, len(ranked))
+	for _, r := range ranked {
+		scores[r.Variant] = r
+	}
+	for i := range pop.Members {
+		if r, ok := scores[strconv.Itoa(pop.Members[i].Variant)]; ok {
+			pop.Members[i].Fitness = r.MeanProgress
+			pop.Members[i].Selection = r.Selection
+			pop.Members[i].Evaluated = true
+		}
+	}
+	sort.SliceStable(pop.Members, func(i, j int) bool {
+		a, b := pop.Members[i], pop.Members[j]
+		if a.Evaluated != b.Evaluated {
+			return a.Evaluated
+		}
+		return a.Fitness > b.Fitness
+	})
+	if err := SavePopulation(pop); err != nil {
+		fmt.Printf("❌ Failed to update population fitness: %v\n", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// pulseCubes drives every cube at movement.translation.actions_per_second for
+// movement.max_lifespan_seconds, adding torque at the rotation rate. It stops
+// as soon as ctx is cancelled, so a shutdown never waits out a full
+// evaluation window. onTick (optional) runs after every pulse round, once
+// all cube positions have been refreshed. The returned map holds how long
+// each cube kept answering before its first failed pulse. When stepped is
+// set the rounds run back to back on a virtual clock (the local simulator),
+// otherwise they follow the wall clock.
+func pulseCubes[T paragon.Numeric](
+	ctx context.Context,
+	cubes []*AgentCube[T],
+	movement MovementConfig,
+	stepped bool,
+	onTick func(elapsed time.Duration),
+) (map[string]time.Duration, error) {
+	duration := movement.Lifespan()
+	torqueEvery := movement.TorqueEvery()
+	interval := time.Second / time.Duration(movement.PulseRate())
+
+	survival := make(map[string]time.Duration, len(cubes))
+	var survivalMu sync.Mutex
+
+	finish := func() map[string]time.Duration {
+		for _, cube := range cubes {
+			if _, dead := survival[cube.Name]; !dead {
+				survival[cube.Name] = duration
+			}
+		}
+		return survival
+	}
+
+	var wg sync.WaitGroup
+	round := func(tick int, elapsed func() time.Duration) {
+		withTorque := torqueEvery > 0 && tick%torqueEvery == 0
+
+		wg.Add(len(cubes))
+		for _, cube := range cubes {
+			go func(cube *AgentCube[T]) {
+				defer wg.Done()
+				if err := cube.Pulse(withTorque, elapsed()); err != nil {
+					survivalMu.Lock()
+					if _, dead := survival[cube.Name]; !dead {
+						survival[cube.Name] = elapsed()
+					}
+					survivalMu.Unlock()
+				}
+			}(cube)
+		}
+		wg.Wait()
+
+		if onTick != nil {
+			onTick(elapsed())
+		}
+	}
+
+	if stepped {
+		// the virtual clock mirrors the ticker: round k happens k+1 intervals in
+		for tick := 0; time.Duration(tick+1)*interval < duration; tick++ {
+			if ctx.Err() != nil {
+				return finish(), ctx.Err()
+			}
+			at := time.Duration(tick+1) * interval
+			round(tick, func() time.Duration { return at })
+		}
+		return finish(), nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+
+	start := time.Now()
+	for tick := 0; ; tick++ {
+		select {
+		case <-ctx.Done():
+			return finish(), ctx.Err()
+		case <-deadline.C:
+			return finish(), nil
+		case <-ticker.C:
+			round(tick, func() time.Duration { return time.Since(start) })
+		}
+	}
+}
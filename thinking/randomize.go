@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/OpenFluke/discover"
+)
+
+// spawnSeed seeds a variant's spawn and goal draws. With per_generation
+// every variant of a generation, whatever its type or mode, sees the same
+// draw; otherwise each variant gets its own. Both are reproducible.
+func (r RandomizationConfig) spawnSeed(master int64, gen int, numType, mode string, variant int) int64 {
+	if r.Seed != 0 {
+		master = r.Seed
+	}
+	if r.PerGeneration {
+		return variantSeed(master, gen, "spawn", "", 0)
+	}
+	return variantSeed(master, gen, numType, mode, variant)
+}
+
+// spawnLayout places n agents around center and gives each its goal. With
+// randomization off this is the fixed Fibonacci sphere of the stage's
+// radius and a goal straight above the center at the stage's offset.
+func spawnLayout(r RandomizationConfig, stage CurriculumStage, center []float64, n int, rng *rand.Rand) (spawns, goals [][]float64) {
+	if !r.Enabled {
+		goal := []float64{center[0], center[1] + stage.Offset(), center[2]}
+		spawns = discover.FibonacciSphere(n, stage.Radius(), center)
+		for range spawns {
+			goals = append(goals, goal)
+		}
+		return spawns, goals
+	}
+
+	rot := identityRotation()
+	if r.RotateSphere {
+		rot = randomRotation(rng)
+	}
+	for _, p := range discover.FibonacciSphere(n, 1, []float64{0, 0, 0}) {
+		radius := stage.Radius() * (1 + r.RadiusJitter*(2*rng.Float64()-1))
+		q := rot.apply(p)
+		spawns = append(spawns, []float64{center[0] + q[0]*radius, center[1] + q[1]*radius, center[2] + q[2]*radius})
+
+		dir := coneDirection(r.GoalAngle, rng)
+		height := stage.Offset() * (1 + r.GoalHeightJitter*(2*rng.Float64()-1))
+		goals = append(goals, []float64{center[0] + dir[0]*height, center[1] + dir[1]*height, center[2] + dir[2]*height})
+	}
+	return spawns, goals
+}
+
+// rotation is a 3×3 rotation matrix, row-major.
+type rotation [3][3]float64
+
+func identityRotation() rotation {
+	return rotation{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+func (m rotation) apply(p []float64) []float64 {
+	out := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		out[i] = m[i][0]*p[0] + m[i][1]*p[1] + m[i][2]*p[2]
+	}
+	return out
+}
+
+// randomRotation draws a uniformly random rotation from a random unit
+// quaternion (Shoemake's method).
+func randomRotation(rng *rand.Rand) rotation {
+	u1, u2, u3 := rng.Float64(), rng.Float64()*2*math.Pi, rng.Float64()*2*math.Pi
+	a, b := math.Sqrt(1-u1), math.Sqrt(u1)
+	w, x, y, z := a*math.Sin(u2), a*math.Cos(u2), b*math.Sin(u3), b*math.Cos(u3)
+	return rotation{
+		{1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w)},
+		{2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w)},
+		{2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y)},
+	}
+}
+
+// coneDirection is a unit vector uniformly distributed within maxDeg
+// degrees of straight up (+y); 180 covers the whole sphere.
+func coneDirection(maxDeg float64, rng *rand.Rand) []float64 {
+	if maxDeg <= 0 {
+		return []float64{0, 1, 0}
+	}
+	cosMax := math.Cos(math.Min(maxDeg, 180) * math.Pi / 180)
+	cosT := 1 - rng.Float64()*(1-cosMax)
+	sinT := math.Sqrt(math.Max(0, 1-cosT*cosT))
+	phi := rng.Float64() * 2 * math.Pi
+	return []float64{sinT * math.Cos(phi), cosT, sinT * math.Sin(phi)}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ScoreVars is everything a scorer may look at for one evaluated cube.
+// Positions are world coordinates; distances are to the cube's goal.
+type ScoreVars struct {
+	Start       []float64
+	End         []float64
+	Goal        []float64
+	Center      []float64
+	Survival    float64 // seconds the cube kept responding
+	Lifespan    float64 // seconds the evaluation was allowed to run
+	InitialDist float64
+	FinalDist   float64
+}
+
+func newScoreVars(start, end, goal, center []float64, survival, lifespan float64) ScoreVars {
+	return ScoreVars{
+		Start:       start,
+		End:         end,
+		Goal:        goal,
+		Center:      center,
+		Survival:    survival,
+		Lifespan:    lifespan,
+		InitialDist: distance(start, goal),
+		FinalDist:   distance(end, goal),
+	}
+}
+
+// Map exposes the vars under the names reward_formula can reference.
+func (v ScoreVars) Map() map[string]float64 {
+	m := map[string]float64{
+		"x_start":      v.Start[0],
+		"y_start":      v.Start[1],
+		"z_start":      v.Start[2],
+		"x_final":      v.End[0],
+		"y_final":      v.End[1],
+		"z_final":      v.End[2],
+		"dist_initial": v.InitialDist,
+		"dist_final":   v.FinalDist,
+		"progress":     v.InitialDist - v.FinalDist,
+		"delta_y":      v.End[1] - v.Start[1],
+		"survival":     v.Survival,
+		"lifespan":     v.Lifespan,
+	}
+	if len(v.Center) == 3 {
+		m["radius_start"] = distance(v.Start, v.Center)
+		m["radius_final"] = distance(v.End, v.Center)
+	}
+	return m
+}
+
+// scoreVarNames lists every variable reward_formula may use.
+func scoreVarNames() []string {
+	zero := []float64{0, 0, 0}
+	names := make([]string, 0, 16)
+	for name := range (ScoreVars{Start: zero, End: zero, Goal: zero, Center: zero}).Map() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Scorer turns one cube's evaluation into a fitness value.
+type Scorer interface {
+	Name() string
+	Score(v ScoreVars) float64
+	// Scale is what Score is divided by when scoring.normalize is set.
+	Scale(v ScoreVars) float64
+}
+
+type scorerFactory func(cfg ScoringConfig) (Scorer, error)
+
+// scorerRegistry is keyed by scoring.method, falling back to scoring.type.
+var scorerRegistry = map[string]scorerFactory{}
+
+// RegisterScorer adds (or replaces) a named scorer.
+func RegisterScorer(name string, factory scorerFactory) {
+	scorerRegistry[name] = factory
+}
+
+type funcScorer struct {
+	name  string
+	score func(v ScoreVars) float64
+	scale func(v ScoreVars) float64
+}
+
+func (s funcScorer) Name() string              { return s.name }
+func (s funcScorer) Score(v ScoreVars) float64 { return s.score(v) }
+func (s funcScorer) Scale(v ScoreVars) float64 { return s.scale(v) }
+
+func staticScorer(s funcScorer) scorerFactory {
+	return func(ScoringConfig) (Scorer, error) { return s, nil }
+}
+
+func init() {
+	distanceToGoal := funcScorer{
+		name:  "distance_to_goal",
+		score: func(v ScoreVars) float64 { return v.InitialDist - v.FinalDist },
+		scale: func(v ScoreVars) float64 { return v.InitialDist },
+	}
+	deltaY := funcScorer{
+		name:  "delta_y",
+		score: func(v ScoreVars) float64 { return v.End[1] - v.Start[1] },
+		scale: func(v ScoreVars) float64 { return v.InitialDist },
+	}
+	survival := funcScorer{
+		name:  "survival_time",
+		score: func(v ScoreVars) float64 { return v.Survival },
+		scale: func(v ScoreVars) float64 { return v.Lifespan },
+	}
+
+	RegisterScorer(distanceToGoal.name, staticScorer(distanceToGoal))
+	RegisterScorer(deltaY.name, staticScorer(deltaY))
+	RegisterScorer(survival.name, staticScorer(survival))
+	RegisterScorer("formula", newFormulaScorer)
+
+	// scoring.type aliases used by existing configs
+	RegisterScorer("goal_distance", staticScorer(distanceToGoal))
+	RegisterScorer("planet_vertical_progress", staticScorer(deltaY))
+	RegisterScorer("survival", staticScorer(survival))
+}
+
+func newFormulaScorer(cfg ScoringConfig) (Scorer, error) {
+	if cfg.RewardFormula == "" {
+		return nil, fmt.Errorf("scoring method formula needs reward_formula")
+	}
+	expr, err := CompileExpr(cfg.RewardFormula, scoreVarNames())
+	if err != nil {
+		return nil, err
+	}
+	return funcScorer{
+		name:  "formula",
+		score: func(v ScoreVars) float64 { return expr.Eval(v.Map()) },
+		scale: func(v ScoreVars) float64 { return v.InitialDist },
+	}, nil
+}
+
+// NewScorer resolves scoring.method, then scoring.type, then a bare
+// reward_formula. Without any match it errors so the caller can fall back.
+func NewScorer(cfg ScoringConfig) (Scorer, error) {
+	for _, key := range []string{cfg.Method, cfg.Type} {
+		if factory, ok := scorerRegistry[key]; ok && key != "" {
+			return factory(cfg)
+		}
+	}
+	if cfg.RewardFormula != "" {
+		return newFormulaScorer(cfg)
+	}
+	return nil, fmt.Errorf("no scorer registered for method %q / type %q", cfg.Method, cfg.Type)
+}
+
+// Validate rejects scoring blocks that would silently not do what they say.
+func (cfg ScoringConfig) Validate() error {
+	if cfg.AccumulateOverLife && !cfg.EvaluateEveryTick {
+		return fmt.Errorf("accumulate_over_life needs evaluate_every_tick: scores are only summed over pulses when every pulse is evaluated")
+	}
+	return nil
+}
+
+// scorerForConfig never fails: an unusable scoring block falls back to the
+// original distance-to-goal progress.
+func scorerForConfig(cfg ScoringConfig) Scorer {
+	s, err := NewScorer(cfg)
+	if err != nil {
+		fmt.Printf("⚠️ %v — falling back to distance_to_goal\n", err)
+		s, _ = scorerRegistry["distance_to_goal"](cfg)
+	}
+	return s
+}
+
+// applyScore runs the scorer and applies normalize, keeping the result
+// JSON-safe (NaN/Inf would break the summary file).
+func applyScore(s Scorer, cfg ScoringConfig, v ScoreVars) float64 {
+	score := s.Score(v)
+	if cfg.Normalize {
+		if scale := s.Scale(v); scale != 0 {
+			score /= math.Abs(scale)
+		}
+	}
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return 0
+	}
+	return score
+}
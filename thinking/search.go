@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// Distribution-based optimizers (es, cmaes) search over the parameters
+// apply_noise_to selects, starting from the generation-0 model. The helpers
+// here turn their parameter vectors into variant files the normal spawn
+// and evaluate path runs.
+
+// searchTemplate loads the generation-0 model as the architecture to fill
+// in, and the parameters the search controls.
+func (e *Experiment[T, M]) searchTemplate() (*paragon.Network[T], []paramRef, error) {
+	basePath := filepath.Join("models", "0", fmt.Sprintf("%s_%s.json", e.NumType, e.Mode.String()))
+	template, err := loadNetworkAs[T](basePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load base model %s: %w", basePath, err)
+	}
+	refs, err := mutationTargets(e.Config.MutationStrategy.ApplyNoiseTo, paramLayout(template), len(template.Layers))
+	if err != nil {
+		return nil, nil, err
+	}
+	return template, refs, nil
+}
+
+// writeSamples saves the search center to centerPath, then materializes
+// every variant that does not exist yet from sample(i) and records it in
+// the manifest with the center as its parent.
+func (e *Experiment[T, M]) writeSamples(
+	ctx context.Context,
+	template *paragon.Network[T],
+	refs []paramRef,
+	center []float64,
+	centerPath string,
+	sample func(i int) ([]float64, VariantRecord),
+) error {
+	mode := e.Mode.String()
+	centerNet, err := cloneNetwork(template)
+	if err != nil {
+		return err
+	}
+	setParams(centerNet, refs, center)
+	if err := os.MkdirAll(filepath.Dir(centerPath), 0755); err != nil {
+		return err
+	}
+	if err := saveNetworkAtomic(centerNet, centerPath); err != nil {
+		return fmt.Errorf("save center: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(variantPath(e.Gen, e.NumType, mode, 0)), 0755); err != nil {
+		return err
+	}
+
+	for i := 0; i < e.Config.VariantCount(); i++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		savePath := variantPath(e.Gen, e.NumType, mode, i)
+		if _, err := os.Stat(savePath); err == nil {
+			continue
+		}
+
+		vals, rec := sample(i)
+		child, err := cloneNetwork(centerNet)
+		if err != nil {
+			return err
+		}
+		setParams(child, refs, vals)
+		if err := saveNetworkAtomic(child, savePath); err != nil {
+			fmt.Printf("❌ Variant %d failed to save: %v\n", i, err)
+			continue
+		}
+
+		rec.NumType, rec.Mode, rec.Variant, rec.Parent = e.NumType, mode, i, centerPath
+		rec.Strategy = e.Config.MutationStrategy
+		if err := RecordVariant(e.Gen, e.Config.Seed, rec); err != nil {
+			fmt.Printf("⚠️ Could not record variant %d in manifest: %v\n", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SimReplay plays recorded sessions back instead of simulating.
+const SimReplay = "replay"
+
+const defaultReplayTolerance = 1e-6
+
+func init() {
+	RegisterSimBackend(SimReplay, func(cfg *ExperimentConfig) (SimBackend, error) {
+		return newReplayBackend(cfg), nil
+	})
+}
+
+func (s SessionConfig) dir() string {
+	if s.Dir == "" {
+		return "models"
+	}
+	return s.Dir
+}
+
+func (s SessionConfig) tolerance() float64 {
+	if s.Tolerance <= 0 {
+		return defaultReplayTolerance
+	}
+	return s.Tolerance
+}
+
+// sessionPath is where a variant's session lives; variant -1 is the
+// held-out evaluation.
+func sessionPath(dir string, gen int, numType, mode string, variant int) string {
+	file := fmt.Sprintf("variant_%d.jsonl", variant)
+	if variant < 0 {
+		file = "heldout.jsonl"
+	}
+	return filepath.Join(dir, strconv.Itoa(gen), "sessions", fmt.Sprintf("%s_%s", numType, mode), file)
+}
+
+// simSession is implemented by backends that work one session per evaluated
+// variant: the recorder and the replayer.
+type simSession interface {
+	BeginSession(path string) error
+	EndSession() error
+}
+
+func (e *Experiment[T, M]) beginSession(variant int) {
+	s, ok := e.Sim.(simSession)
+	if !ok {
+		return
+	}
+	path := sessionPath(e.Config.Sessions.dir(), e.Gen, e.NumType, e.Mode.String(), variant)
+	if err := s.BeginSession(path); err != nil {
+		fmt.Printf("❌ Session %s: %v\n", path, err)
+	}
+}
+
+func (e *Experiment[T, M]) endSession() {
+	if s, ok := e.Sim.(simSession); ok {
+		if err := s.EndSession(); err != nil {
+			fmt.Printf("❌ Failed to close session: %v\n", err)
+		}
+	}
+}
+
+// Session operations, one per SimBackend call evaluation makes. Scans are
+// not part of a session: only the status poller issues them.
+const (
+	opBegin    = "begin"
+	opSpawn    = "spawn"
+	opUnfreeze = "unfreeze"
+	opForce    = "force"
+	opTorque   = "torque"
+	opPosition = "position"
+	opDespawn  = "despawn"
+	opDestroy  = "destroy"
+)
+
+// simEvent is one line of a session file: a request and what came back.
+type simEvent struct {
+	At     float64   `json:"t"`    // seconds since the session began
+	Time   string    `json:"time"` // wall clock, RFC 3339
+	Op     string    `json:"op"`
+	Cube   string    `json:"cube,omitempty"`
+	Args   []float64 `json:"args,omitempty"`   // spawn position, force or torque sent
+	Result []float64 `json:"result,omitempty"` // position returned
+	Name   string    `json:"name,omitempty"`   // name the cube was spawned under; the backend on begin
+	Error  string    `json:"error,omitempty"`
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// recordingBackend passes every call through to the backend it wraps and,
+// while a session is open, appends it to the session file.
+type recordingBackend struct {
+	SimBackend
+
+	mu    sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+	start time.Time
+}
+
+func newRecordingBackend(inner SimBackend) *recordingBackend {
+	return &recordingBackend{SimBackend: inner}
+}
+
+func (r *recordingBackend) BeginSession(path string) error {
+	if err := r.EndSession(); err != nil {
+		fmt.Printf("⚠️ Failed to close previous session: %v\n", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.file, r.w, r.start = f, bufio.NewWriter(f), time.Now()
+	r.mu.Unlock()
+	r.record(simEvent{Op: opBegin, Name: r.SimBackend.Name()})
+	fmt.Printf("🎙️ Recording session → %s\n", path)
+	return nil
+}
+
+func (r *recordingBackend) EndSession() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.w.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.w = nil, nil
+	return err
+}
+
+func (r *recordingBackend) record(ev simEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return
+	}
+	now := time.Now()
+	ev.At = now.Sub(r.start).Seconds()
+	ev.Time = now.Format(time.RFC3339Nano)
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	r.w.Write(append(data, '\n'))
+}
+
+func (r *recordingBackend) SpawnCube(name string, pos []float64) (string, error) {
+	spawned, err := r.SimBackend.SpawnCube(name, pos)
+	r.record(simEvent{Op: opSpawn, Cube: name, Args: pos, Name: spawned, Error: errString(err)})
+	return spawned, err
+}
+
+func (r *recordingBackend) UnfreezeAll() error {
+	err := r.SimBackend.UnfreezeAll()
+	r.record(simEvent{Op: opUnfreeze, Error: errString(err)})
+	return err
+}
+
+func (r *recordingBackend) ApplyForce(name string, force []float64) error {
+	err := r.SimBackend.ApplyForce(name, force)
+	r.record(simEvent{Op: opForce, Cube: name, Args: force, Error: errString(err)})
+	return err
+}
+
+func (r *recordingBackend) ApplyTorque(name string, torque []float64) error {
+	err := r.SimBackend.ApplyTorque(name, torque)
+	r.record(simEvent{Op: opTorque, Cube: name, Args: torque, Error: errString(err)})
+	return err
+}
+
+func (r *recordingBackend) Position(name string) ([]float64, error) {
+	pos, err := r.SimBackend.Position(name)
+	r.record(simEvent{Op: opPosition, Cube: name, Result: pos, Error: errString(err)})
+	return pos, err
+}
+
+func (r *recordingBackend) DespawnCube(name string) error {
+	err := r.SimBackend.DespawnCube(name)
+	r.record(simEvent{Op: opDespawn, Cube: name, Error: errString(err)})
+	return err
+}
+
+func (r *recordingBackend) DestroyAll() error {
+	err := r.SimBackend.DestroyAll()
+	r.record(simEvent{Op: opDestroy, Error: errString(err)})
+	return err
+}
+
+// replayBackend answers every call from the recorded session of the variant
+// being evaluated. Calls are matched per operation and cube in recorded
+// order, so concurrent pulses replay regardless of interleaving. Arguments
+// that differ from the recording (forces, spawn positions) are counted as
+// divergences: the recorded response is served all the same.
+type replayBackend struct {
+	tolerance float64
+	planets   []SimPlanet
+
+	mu          sync.Mutex
+	path        string
+	queues      map[string][]simEvent
+	live        map[string]bool
+	divergences int
+	exhausted   int
+}
+
+func newReplayBackend(cfg *ExperimentConfig) *replayBackend {
+	r := &replayBackend{tolerance: cfg.Sessions.tolerance(), live: map[string]bool{}}
+	for _, name := range cfg.AllPlanets() {
+		pos, err := parseVec3(name)
+		if err != nil {
+			continue
+		}
+		r.planets = append(r.planets, SimPlanet{
+			Name:   name,
+			Center: []float64{pos.X * planetSpacing, pos.Y * planetSpacing, pos.Z * planetSpacing},
+			Host:   SimReplay,
+		})
+	}
+	return r
+}
+
+func (r *replayBackend) Name() string { return SimReplay }
+
+func (r *replayBackend) BeginSession(path string) error {
+	r.EndSession()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("no recorded session: %w", err)
+	}
+	defer f.Close()
+
+	queues := map[string][]simEvent{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var ev simEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if ev.Op != opBegin {
+			queues[ev.Op+"|"+ev.Cube] = append(queues[ev.Op+"|"+ev.Cube], ev)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.path, r.queues, r.live = path, queues, map[string]bool{}
+	r.divergences, r.exhausted = 0, 0
+	r.mu.Unlock()
+	fmt.Printf("📼 Replaying session ← %s\n", path)
+	return nil
+}
+
+func (r *replayBackend) EndSession() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return nil
+	}
+	if r.divergences > 0 || r.exhausted > 0 {
+		fmt.Printf("⚠️ Replay of %s: %d diverged call(s), %d call(s) past the recording\n", r.path, r.divergences, r.exhausted)
+	}
+	r.path, r.queues = "", nil
+	return nil
+}
+
+// next pops the recorded response to op on cube, comparing args with what
+// was sent at the time.
+func (r *replayBackend) next(op, cube string, args []float64) (simEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := op + "|" + cube
+	q := r.queues[key]
+	if len(q) == 0 {
+		r.exhausted++
+		return simEvent{}, fmt.Errorf("replay: no recorded %s for %q", op, cube)
+	}
+	ev := q[0]
+	r.queues[key] = q[1:]
+
+	if !sameArgs(ev.Args, args, r.tolerance) {
+		if r.divergences == 0 {
+			fmt.Printf("⚠️ Replay diverged at %s %q (t=%.3fs): recorded %v, sent %v\n", op, cube, ev.At, ev.Args, args)
+		}
+		r.divergences++
+	}
+	if ev.Error != "" {
+		return ev, fmt.Errorf("%s", ev.Error)
+	}
+	return ev, nil
+}
+
+func sameArgs(a, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// ScanPlanets reports the configured planets and the cubes the session has
+// spawned so far.
+func (r *replayBackend) ScanPlanets() (SimScan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scan := SimScan{Planets: r.planets, Cubes: make(map[string]string, len(r.live))}
+	for name := range r.live {
+		scan.Cubes[name] = SimReplay
+	}
+	return scan, nil
+}
+
+func (r *replayBackend) SpawnCube(name string, pos []float64) (string, error) {
+	ev, err := r.next(opSpawn, name, pos)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.live[ev.Name] = true
+	r.mu.Unlock()
+	return ev.Name, nil
+}
+
+func (r *replayBackend) UnfreezeAll() error {
+	_, err := r.next(opUnfreeze, "", nil)
+	return err
+}
+
+func (r *replayBackend) ApplyForce(name string, force []float64) error {
+	_, err := r.next(opForce, name, force)
+	return err
+}
+
+func (r *replayBackend) ApplyTorque(name string, torque []float64) error {
+	_, err := r.next(opTorque, name, torque)
+	return err
+}
+
+func (r *replayBackend) Position(name string) ([]float64, error) {
+	ev, err := r.next(opPosition, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ev.Result, nil
+}
+
+func (r *replayBackend) DespawnCube(name string) error {
+	_, err := r.next(opDespawn, name, nil)
+	r.mu.Lock()
+	delete(r.live, name)
+	r.mu.Unlock()
+	return err
+}
+
+func (r *replayBackend) DestroyAll() error {
+	_, err := r.next(opDestroy, "", nil)
+	r.mu.Lock()
+	r.live = map[string]bool{}
+	r.mu.Unlock()
+	return err
+}
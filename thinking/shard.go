@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultPodRetry       = 10 * time.Second
+	defaultPodMaxAttempts = 3
+)
+
+func (p PodsConfig) hosts() []string {
+	if len(p.Hosts) == 0 {
+		return []string{gameHost()}
+	}
+	return p.Hosts
+}
+
+func (p PodsConfig) startPort() int {
+	if p.StartPort <= 0 {
+		return primordiaPort
+	}
+	return p.StartPort
+}
+
+func (p PodsConfig) portStep() int {
+	if p.PortStep <= 0 {
+		return 3
+	}
+	return p.PortStep
+}
+
+func (p PodsConfig) numPods() int {
+	if p.NumPods <= 0 {
+		return 1
+	}
+	return p.NumPods
+}
+
+func (p PodsConfig) retry() time.Duration {
+	if p.RetrySeconds <= 0 {
+		return defaultPodRetry
+	}
+	return time.Duration(p.RetrySeconds) * time.Second
+}
+
+func (p PodsConfig) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultPodMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// simPod is one simulator instance and how many variants it evaluates at
+// once.
+type simPod struct {
+	name  string
+	sim   SimBackend
+	slots int
+	retry time.Duration
+
+	// spawnMu keeps a variant's spawn and unfreeze apart from another's,
+	// since unfreezing reaches every cube on the pod
+	spawnMu sync.Mutex
+
+	mu        sync.Mutex
+	up        bool // answered a probe and has not failed since
+	down      bool
+	downUntil time.Time
+}
+
+// newSimPods builds every configured pod. Primordia pods that do not answer
+// yet are kept: they join as soon as a probe reaches them.
+func newSimPods(cfg *ExperimentConfig) ([]*simPod, error) {
+	var sims []SimBackend
+	var names []string
+	if cfg.SimName() == SimPrimordia {
+		if cfg.Movement.TorqueEvery() > 0 {
+			fmt.Println("⚠️ movement.rotation is ignored: Primordia has no torque command, rotation only works with sim: local")
+		}
+		for _, host := range cfg.Pods.hosts() {
+			for i := 0; i < cfg.Pods.numPods(); i++ {
+				p := newPrimordiaBackendAt(host, cfg.Pods.startPort()+i*cfg.Pods.portStep())
+				sims = append(sims, withRecording(cfg, p))
+				names = append(names, p.addr())
+			}
+		}
+	} else {
+		for i := 0; i < cfg.Pods.numPods(); i++ {
+			sim, err := NewSimBackend(cfg)
+			if err != nil {
+				return nil, err
+			}
+			sims = append(sims, sim)
+			names = append(names, fmt.Sprintf("%s#%d", sim.Name(), i))
+		}
+	}
+
+	// every variant spawns at the same positions around each planet, so a
+	// Primordia pod holding two variants would stack their cubes on each
+	// other; only the local simulator's point masses can share a world
+	slots := 1
+	if cfg.SimName() != SimPrimordia {
+		slots = cfg.podSlots()
+	}
+	pods := make([]*simPod, len(sims))
+	for i, sim := range sims {
+		pods[i] = &simPod{name: names[i], sim: sim, slots: slots, retry: cfg.Pods.retry()}
+		if _, ok := sim.(simSession); ok {
+			pods[i].slots = 1 // a session covers one variant at a time
+		}
+	}
+	return pods, nil
+}
+
+// podSlots is how many variants fit on a local pod within max_needed cubes.
+func (c *ExperimentConfig) podSlots() int {
+	perVariant := len(c.TrainingPlanets()) * c.EvaluationSpawnsPerPlanet
+	if c.MaxNeeded <= 0 || perVariant <= 0 {
+		return 1
+	}
+	return max(1, c.MaxNeeded/perVariant)
+}
+
+func podSims(pods []*simPod) []SimBackend {
+	sims := make([]SimBackend, len(pods))
+	for i, p := range pods {
+		sims[i] = p.sim
+	}
+	return sims
+}
+
+// healthy probes the pod until it first answers and again after each
+// failure, resting retry_seconds between probes. A pod that is up is not
+// probed: a failure during a variant marks it down instead.
+func (p *simPod) healthy() bool {
+	p.mu.Lock()
+	up, resting := p.up, time.Now().Before(p.downUntil)
+	p.mu.Unlock()
+	if up {
+		return true
+	}
+	if resting {
+		return false
+	}
+	if _, err := p.sim.ScanPlanets(); err != nil {
+		p.markDown(err)
+		return false
+	}
+	p.mu.Lock()
+	recovered := p.down
+	p.up, p.down = true, false
+	p.mu.Unlock()
+	if recovered {
+		fmt.Printf("📡 Pod %s is back\n", p.name)
+	}
+	return true
+}
+
+// markDown rests the pod for pods.retry_seconds before it is probed again.
+func (p *simPod) markDown(err error) {
+	p.mu.Lock()
+	wasUp := !p.down
+	p.up, p.down = false, true
+	p.downUntil = time.Now().Add(p.retry)
+	p.mu.Unlock()
+	if wasUp {
+		fmt.Printf("📡 Pod %s is not responding (%v) — probing every %s\n", p.name, err, p.retry)
+	}
+}
+
+// firstHealthyPod is where one-off evaluations (held-out) run; with every
+// pod down it is the first one, which then fails like any variant would.
+func firstHealthyPod(pods []*simPod) *simPod {
+	for _, p := range pods {
+		if p.healthy() {
+			return p
+		}
+	}
+	return pods[0]
+}
+
+// evaluateVariants runs every variant of exp that has no summary yet,
+// spread over the pods. Each pod takes up to its slots of variants at once,
+// and only once it has answered a probe. A variant whose pod fails during its
+// evaluation is discarded and re-queued for another pod, up to
+// pods.max_attempts times. When no pod has answered or been working for
+// max_attempts × retry_seconds, the variants still queued are given up. It
+// reports false when the loop was stopped or cancelled.
+func evaluateVariants(ctx context.Context, cfg *ExperimentConfig, exp ExperimentRunner, pods []*simPod, gen int) bool {
+	numType, mode := exp.GetNumType(), exp.GetMode()
+	resultsDir := filepath.Join("models", strconv.Itoa(gen), fmt.Sprintf("mutated_%s_%s", numType, mode), "results")
+	summaryPath := func(i int) string {
+		return filepath.Join(resultsDir, fmt.Sprintf("variant_%d_summary.json", i))
+	}
+
+	queue := make(chan int, cfg.VariantCount())
+	for i := 0; i < cfg.VariantCount(); i++ {
+		if _, err := os.Stat(summaryPath(i)); err == nil {
+			AppendStatus(gen, numType, mode, i, "Skipped", "Summary already exists")
+			continue
+		}
+		queue <- i
+	}
+	remaining := len(queue)
+	if remaining == 0 {
+		return true
+	}
+
+	var (
+		mu       sync.Mutex
+		attempts = map[int]int{}
+		stopped  bool
+		busy     int          // variants being evaluated right now
+		lastUp   = time.Now() // last time a pod answered or finished a variant
+		done     = make(chan struct{})
+	)
+	deadline := time.Duration(cfg.Pods.maxAttempts()) * pods[0].retry
+	closeDone := sync.OnceFunc(func() { close(done) })
+	finish := func() {
+		mu.Lock()
+		remaining--
+		if remaining == 0 {
+			closeDone()
+		}
+		mu.Unlock()
+	}
+	halt := func() {
+		mu.Lock()
+		stopped = true
+		mu.Unlock()
+		closeDone()
+	}
+	// giveUp fails every queued variant once all pods have been down for
+	// the deadline.
+	giveUp := func() {
+		mu.Lock()
+		stalled := busy == 0 && time.Since(lastUp) >= deadline
+		mu.Unlock()
+		if !stalled {
+			return
+		}
+		for {
+			select {
+			case i := <-queue:
+				AppendStatus(gen, numType, mode, i, "Failed", fmt.Sprintf("No pod answered for %s", deadline))
+				finish()
+			default:
+				return
+			}
+		}
+	}
+
+	worker := func(pod *simPod) {
+		for {
+			if !pod.healthy() {
+				giveUp()
+				select {
+				case <-time.After(pod.retry):
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			mu.Lock()
+			lastUp = time.Now()
+			mu.Unlock()
+
+			var i int
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case i = <-queue:
+			}
+			// select picks at random among ready cases, so a cancel can
+			// still hand over a variant
+			if ctx.Err() != nil {
+				return
+			}
+
+			if !controller.AtVariantBoundary(ctx) {
+				if ctx.Err() == nil {
+					AppendStatus(gen, numType, mode, i, "Stopped", "Episode loop stopped by control")
+				}
+				halt()
+				return
+			}
+
+			mu.Lock()
+			busy++
+			mu.Unlock()
+			faults := &podFaults{SimBackend: pod.sim}
+			if !runVariantOnPod(ctx, exp.OnPod(faults), pod, gen, i) {
+				halt()
+				return
+			}
+
+			fault := faults.first()
+			if fault == nil {
+				finish()
+			} else {
+				// the pod failed mid-evaluation: its scores cannot be trusted
+				pod.markDown(fault)
+				_ = os.Remove(summaryPath(i))
+				mu.Lock()
+				attempts[i]++
+				tries := attempts[i]
+				mu.Unlock()
+				if tries >= cfg.Pods.maxAttempts() {
+					AppendStatus(gen, numType, mode, i, "Failed", fmt.Sprintf("Gave up after %d pod failure(s)", tries))
+					finish()
+				} else {
+					AppendStatus(gen, numType, mode, i, "Requeued", fmt.Sprintf("Pod %s failed: %v", pod.name, fault))
+					queue <- i
+				}
+			}
+
+			mu.Lock()
+			busy--
+			lastUp = time.Now()
+			mu.Unlock()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, pod := range pods {
+		for s := 0; s < pod.slots; s++ {
+			wg.Add(1)
+			go func(pod *simPod) {
+				defer wg.Done()
+				worker(pod)
+			}(pod)
+		}
+	}
+	wg.Wait()
+
+	return !stopped && ctx.Err() == nil
+}
+
+// podFaults watches one variant's calls to its pod and keeps the first error
+// that means the pod itself failed. Read timeouts are left out: those are
+// one slow cube, which score_if_timeout already covers.
+type podFaults struct {
+	SimBackend
+
+	mu  sync.Mutex
+	err error
+}
+
+func (f *podFaults) note(err error) error {
+	if isPodFault(err) {
+		f.mu.Lock()
+		if f.err == nil {
+			f.err = err
+		}
+		f.mu.Unlock()
+	}
+	return err
+}
+
+func (f *podFaults) first() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *podFaults) SpawnCube(name string, pos []float64) (string, error) {
+	spawned, err := f.SimBackend.SpawnCube(name, pos)
+	return spawned, f.note(err)
+}
+
+func (f *podFaults) UnfreezeAll() error { return f.note(f.SimBackend.UnfreezeAll()) }
+
+func (f *podFaults) ApplyForce(name string, force []float64) error {
+	return f.note(f.SimBackend.ApplyForce(name, force))
+}
+
+func (f *podFaults) Position(name string) ([]float64, error) {
+	pos, err := f.SimBackend.Position(name)
+	return pos, f.note(err)
+}
+
+func (f *podFaults) DespawnCube(name string) error { return f.note(f.SimBackend.DespawnCube(name)) }
+
+func (f *podFaults) DestroyAll() error { return f.note(f.SimBackend.DestroyAll()) }
+
+// BeginSession and EndSession pass through, so recording and replay still
+// see one session per variant.
+func (f *podFaults) BeginSession(path string) error {
+	if s, ok := f.SimBackend.(simSession); ok {
+		return s.BeginSession(path)
+	}
+	return nil
+}
+
+func (f *podFaults) EndSession() error {
+	if s, ok := f.SimBackend.(simSession); ok {
+		return s.EndSession()
+	}
+	return nil
+}
+
+// isPodFault reports whether err came from the connection to the pod
+// breaking (refused, reset, closed) rather than from a slow reply or the
+// simulator itself.
+func isPodFault(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !netErr.Timeout()
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// runVariantOnPod is one variant's spawn → run → clean-up on a pod. It
+// reports false when the loop was cancelled, after despawning the variant.
+func runVariantOnPod(ctx context.Context, run ExperimentRunner, pod *simPod, gen, i int) bool {
+	numType, mode := run.GetNumType(), run.GetMode()
+	cancelled := func() bool {
+		if ctx.Err() == nil {
+			return false
+		}
+		run.DespawnAgents()
+		AppendStatus(gen, numType, mode, i, "Cancelled", "Episode loop cancelled — agents despawned")
+		return true
+	}
+
+	AppendStatus(gen, numType, mode, i, "SpawningAgents", fmt.Sprintf("Spawning agents for variant on %s", pod.name))
+
+	pod.spawnMu.Lock()
+	run.SpawnAgentsOnPlanets(ctx, i)
+	run.UnfreezeAgents(ctx)
+	pod.spawnMu.Unlock()
+	if cancelled() {
+		return false
+	}
+
+	AppendStatus(gen, numType, mode, i, "Running", "Agents running...")
+
+	run.RunAndMonitorAgents(ctx, i)
+	if cancelled() {
+		return false
+	}
+
+	AppendStatus(gen, numType, mode, i, "Finished", "Run and monitor completed")
+
+	// a pod shared with other variants only loses this variant's cubes
+	if pod.slots > 1 {
+		run.DespawnAgents()
+	} else {
+		run.NukeAllAgents()
+	}
+
+	AppendStatus(gen, numType, mode, i, "Cleaned", "Agents nuked")
+	return true
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in simulators selectable with "sim"
+const (
+	SimPrimordia = "primordia" // the Biofoundry server on TCP (default)
+	SimLocal     = "local"     // the in-process LocalWorld
+)
+
+// SimName is the configured simulator, defaulting to Primordia.
+func (c *ExperimentConfig) SimName() string {
+	if c.Sim == "" {
+		return SimPrimordia
+	}
+	return c.Sim
+}
+
+// Stepped reports whether evaluation runs on a virtual clock: the local
+// simulator steps as fast as it can unless local_sim.realtime is set, and
+// replays never wait.
+func (c *ExperimentConfig) Stepped() bool {
+	switch c.SimName() {
+	case SimLocal:
+		return !c.LocalSim.Realtime
+	case SimReplay:
+		return true
+	}
+	return false
+}
+
+// PulseMovement is the movement evaluation pulses with. Primordia only
+// takes forces, so rotation is dropped there; the local simulator (and
+// replays of it) keep it.
+func (c *ExperimentConfig) PulseMovement() MovementConfig {
+	m := c.Movement
+	if c.SimName() == SimPrimordia {
+		m.Rotation = MovementSubConfig{}
+	}
+	return m
+}
+
+// SimPlanet is one planet a backend reports.
+type SimPlanet struct {
+	Name   string
+	Center []float64
+	Host   string
+	Port   int
+}
+
+// SimScan is a snapshot of the world: its planets and the live cubes,
+// keyed by name with the host serving each one.
+type SimScan struct {
+	Planets []SimPlanet
+	Cubes   map[string]string
+}
+
+// SimBackend is everything evaluation needs from a simulator. Cubes are
+// addressed by the name SpawnCube returns, which may differ from the one
+// requested (Primordia suffixes base cubes with _BASE).
+type SimBackend interface {
+	Name() string
+	ScanPlanets() (SimScan, error)
+	SpawnCube(name string, pos []float64) (string, error)
+	UnfreezeAll() error
+	ApplyForce(name string, force []float64) error
+	ApplyTorque(name string, torque []float64) error
+	Position(name string) ([]float64, error)
+	DespawnCube(name string) error
+	DestroyAll() error
+}
+
+type simBackendFactory func(cfg *ExperimentConfig) (SimBackend, error)
+
+// simRegistry is keyed by the config's "sim".
+var simRegistry = map[string]simBackendFactory{}
+
+// RegisterSimBackend adds (or replaces) a named simulator backend.
+func RegisterSimBackend(name string, factory simBackendFactory) {
+	simRegistry[name] = factory
+}
+
+// NewSimBackend builds the backend cfg selects.
+func NewSimBackend(cfg *ExperimentConfig) (SimBackend, error) {
+	name := cfg.SimName()
+	factory, ok := simRegistry[name]
+	if !ok {
+		names := make([]string, 0, len(simRegistry))
+		for n := range simRegistry {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown sim %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	b, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return withRecording(cfg, b), nil
+}
+
+// withRecording wraps b in a session recorder when sessions.record is set.
+func withRecording(cfg *ExperimentConfig, b SimBackend) SimBackend {
+	if !cfg.Sessions.Record {
+		return b
+	}
+	if cfg.SimName() == SimReplay {
+		fmt.Println("⚠️ sessions.record is ignored while replaying")
+		return b
+	}
+	return newRecordingBackend(b)
+}
+
+// activeSims are the pods the episode loop runs on, so the status poller
+// scans the same worlds.
+var (
+	activeSims   []SimBackend
+	activeSimsMu sync.Mutex
+)
+
+func setActiveSims(sims []SimBackend) {
+	activeSimsMu.Lock()
+	activeSims = sims
+	activeSimsMu.Unlock()
+}
+
+// currentSims are the loop's pods or, before any loop has started, the
+// backend the loaded config selects (Primordia without a config).
+func currentSims() []SimBackend {
+	activeSimsMu.Lock()
+	defer activeSimsMu.Unlock()
+	if len(activeSims) == 0 {
+		var sim SimBackend
+		var err error
+		if experimentConfig != nil {
+			sim, err = NewSimBackend(experimentConfig)
+		}
+		if sim == nil || err != nil {
+			sim = newPrimordiaBackend()
+		}
+		activeSims = []SimBackend{sim}
+	}
+	return activeSims
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+func init() {
+	RegisterSimBackend(SimLocal, func(cfg *ExperimentConfig) (SimBackend, error) {
+		return NewLocalWorld(cfg), nil
+	})
+}
+
+const (
+	defaultLocalPlanetRadius = 50.0
+	defaultLocalGravity      = 2.0
+	defaultLocalMass         = 1.0
+	defaultLocalDamping      = 0.1
+)
+
+func (l LocalSimConfig) radiusFor(planet string) float64 {
+	if r, ok := l.PlanetRadii[planet]; ok && r > 0 {
+		return r
+	}
+	if l.PlanetRadius > 0 {
+		return l.PlanetRadius
+	}
+	return defaultLocalPlanetRadius
+}
+
+type localPlanet struct {
+	Name   string
+	Center []float64
+	Radius float64
+}
+
+type localBody struct {
+	Pos, Vel, AngVel []float64
+	Frozen           bool
+}
+
+// LocalWorld is a kinematic stand-in for Primordia. Planets are point
+// gravity wells (surface gravity falling off with 1/r²) with a solid
+// surface at their radius. Cubes are point masses that spawn frozen, like
+// on the server, and advance one step of dt every time a force is applied,
+// so a pulse is one step regardless of wall-clock time.
+type LocalWorld struct {
+	mu      sync.Mutex
+	cfg     LocalSimConfig
+	dt      float64
+	planets []localPlanet
+	bodies  map[string]*localBody
+}
+
+// NewLocalWorld builds the world from every configured planet; a step
+// lasts one pulse at movement.translation.actions_per_second.
+func NewLocalWorld(cfg *ExperimentConfig) *LocalWorld {
+	w := &LocalWorld{
+		cfg:    cfg.LocalSim,
+		dt:     1 / float64(cfg.Movement.PulseRate()),
+		bodies: map[string]*localBody{},
+	}
+	for _, name := range cfg.AllPlanets() {
+		pos, err := parseVec3(name)
+		if err != nil {
+			fmt.Printf("⚠️ Local sim skipping planet %q: %v\n", name, err)
+			continue
+		}
+		w.planets = append(w.planets, localPlanet{
+			Name:   name,
+			Center: []float64{pos.X * planetSpacing, pos.Y * planetSpacing, pos.Z * planetSpacing},
+			Radius: cfg.LocalSim.radiusFor(name),
+		})
+	}
+	return w
+}
+
+func (w *LocalWorld) Name() string { return SimLocal }
+
+// ScanPlanets reports the configured planets and every live cube.
+func (w *LocalWorld) ScanPlanets() (SimScan, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	scan := SimScan{Cubes: make(map[string]string, len(w.bodies))}
+	for _, p := range w.planets {
+		scan.Planets = append(scan.Planets, SimPlanet{Name: p.Name, Center: append([]float64(nil), p.Center...), Host: SimLocal})
+	}
+	for name := range w.bodies {
+		scan.Cubes[name] = SimLocal
+	}
+	return scan, nil
+}
+
+// SpawnCube creates a frozen cube and returns the name the server would
+// give it.
+func (w *LocalWorld) SpawnCube(name string, pos []float64) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	name += "_BASE"
+	b := &localBody{Pos: append([]float64(nil), pos...), Vel: make([]float64, 3), AngVel: make([]float64, 3), Frozen: true}
+	w.collide(b)
+	w.bodies[name] = b
+	return name, nil
+}
+
+func (w *LocalWorld) UnfreezeAll() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.bodies {
+		b.Frozen = false
+	}
+	return nil
+}
+
+// SetFrozen freezes or releases a single cube, as the server's freeze_cube.
+func (w *LocalWorld) SetFrozen(name string, frozen bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b, ok := w.bodies[name]
+	if !ok {
+		return fmt.Errorf("no cube %q", name)
+	}
+	b.Frozen = frozen
+	return nil
+}
+
+// ApplyForce advances the cube one step under force plus gravity.
+func (w *LocalWorld) ApplyForce(name string, force []float64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b, ok := w.bodies[name]
+	if !ok {
+		return fmt.Errorf("no cube %q", name)
+	}
+	if b.Frozen {
+		return nil
+	}
+
+	mass := w.cfg.Mass
+	if mass <= 0 {
+		mass = defaultLocalMass
+	}
+	damping := w.cfg.Damping
+	if damping <= 0 {
+		damping = defaultLocalDamping
+	}
+	g := w.gravity(b.Pos)
+	for k := 0; k < 3; k++ {
+		b.Vel[k] = (b.Vel[k] + (force[k]/mass+g[k])*w.dt) * math.Max(0, 1-damping*w.dt)
+		b.Pos[k] += b.Vel[k] * w.dt
+	}
+	w.collide(b)
+	return nil
+}
+
+// ApplyTorque spins the cube; rotation does not move a point mass.
+func (w *LocalWorld) ApplyTorque(name string, torque []float64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b, ok := w.bodies[name]
+	if !ok {
+		return fmt.Errorf("no cube %q", name)
+	}
+	if b.Frozen {
+		return nil
+	}
+	mass := w.cfg.Mass
+	if mass <= 0 {
+		mass = defaultLocalMass
+	}
+	for k := 0; k < 3; k++ {
+		b.AngVel[k] += torque[k] / mass * w.dt
+	}
+	return nil
+}
+
+func (w *LocalWorld) Position(name string) ([]float64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b, ok := w.bodies[name]
+	if !ok {
+		return nil, fmt.Errorf("no cube %q", name)
+	}
+	return append([]float64(nil), b.Pos...), nil
+}
+
+func (w *LocalWorld) DespawnCube(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.bodies[name]; !ok {
+		return fmt.Errorf("no cube %q", name)
+	}
+	delete(w.bodies, name)
+	return nil
+}
+
+func (w *LocalWorld) DestroyAll() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bodies = map[string]*localBody{}
+	return nil
+}
+
+// gravity sums every planet's pull at pos: surface gravity at the radius,
+// falling off with the square of the distance.
+func (w *LocalWorld) gravity(pos []float64) []float64 {
+	surface := w.cfg.Gravity
+	if surface <= 0 {
+		surface = defaultLocalGravity
+	}
+	g := make([]float64, 3)
+	for _, p := range w.planets {
+		r := distance(pos, p.Center)
+		if r == 0 {
+			continue
+		}
+		pull := surface * (p.Radius * p.Radius) / (math.Max(r, p.Radius) * math.Max(r, p.Radius))
+		for k := 0; k < 3; k++ {
+			g[k] += pull * (p.Center[k] - pos[k]) / r
+		}
+	}
+	return g
+}
+
+// collide lifts a cube that sank below a planet's surface back onto it and
+// drops the velocity into the surface.
+func (w *LocalWorld) collide(b *localBody) {
+	for _, p := range w.planets {
+		r := distance(b.Pos, p.Center)
+		if r >= p.Radius || r == 0 {
+			continue
+		}
+		normal := make([]float64, 3)
+		for k := 0; k < 3; k++ {
+			normal[k] = (b.Pos[k] - p.Center[k]) / r
+			b.Pos[k] = p.Center[k] + normal[k]*p.Radius
+		}
+		if into := b.Vel[0]*normal[0] + b.Vel[1]*normal[1] + b.Vel[2]*normal[2]; into < 0 {
+			for k := 0; k < 3; k++ {
+				b.Vel[k] -= into * normal[k]
+			}
+		}
+	}
+}
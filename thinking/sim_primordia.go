@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenFluke/construct"
+	"github.com/OpenFluke/discover"
+)
+
+// Primordia connection defaults, as the Biofoundry server ships them
+const (
+	primordiaPort      = 14000
+	primordiaAuthPass  = "my_secure_password"
+	primordiaDelimiter = "<???DONE???---"
+)
+
+// errPrimordiaTorque is returned if torque ever reaches a Primordia pod;
+// rotation is switched off for primordia before pulsing starts.
+var errPrimordiaTorque = errors.New("primordia has no apply_torque command")
+
+// cubeReadTimeout matches the deadline construct uses for server replies
+const cubeReadTimeout = 3 * time.Second
+
+func init() {
+	RegisterSimBackend(SimPrimordia, func(cfg *ExperimentConfig) (SimBackend, error) {
+		return newPrimordiaBackendAt(cfg.Pods.hosts()[0], cfg.Pods.startPort()), nil
+	})
+}
+
+// primordiaBackend drives the Biofoundry server over TCP. Each spawned cube
+// keeps its own persistent connection for forces and position queries;
+// world-wide commands open one of their own and scans go through discover.
+type primordiaBackend struct {
+	host      string
+	port      int
+	authPass  string
+	delimiter string
+
+	mu    sync.Mutex
+	conns map[string]*primordiaConn
+}
+
+type primordiaConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// newPrimordiaBackend targets GAME_HOST (default localhost) on port 14000.
+func newPrimordiaBackend() *primordiaBackend {
+	return newPrimordiaBackendAt(gameHost(), primordiaPort)
+}
+
+func newPrimordiaBackendAt(host string, port int) *primordiaBackend {
+	return &primordiaBackend{
+		host:      host,
+		port:      port,
+		authPass:  primordiaAuthPass,
+		delimiter: primordiaDelimiter,
+		conns:     map[string]*primordiaConn{},
+	}
+}
+
+func gameHost() string {
+	if host := os.Getenv("GAME_HOST"); host != "" {
+		return host
+	}
+	return "localhost"
+}
+
+func (p *primordiaBackend) Name() string { return SimPrimordia }
+
+func (p *primordiaBackend) String() string { return p.addr() }
+
+func (p *primordiaBackend) addr() string { return fmt.Sprintf("%s:%d", p.host, p.port) }
+
+// ScanPlanets scans this pod. A pod that does not answer is an error, which
+// is how the scheduler tells it has gone down.
+func (p *primordiaBackend) ScanPlanets() (SimScan, error) {
+	d := discover.NewDiscover(discover.Config{
+		Hosts:      []string{p.host},
+		StartPort:  p.port,
+		PortStep:   3,
+		NumPods:    1,
+		AuthPass:   p.authPass,
+		Delimiter:  p.delimiter,
+		TimeoutSec: 5,
+	})
+	d.ScanAll()
+	for _, res := range d.Results {
+		if !res.Success {
+			return SimScan{}, fmt.Errorf("%s: %s", p.addr(), res.Error)
+		}
+	}
+
+	scan := SimScan{Cubes: d.Cubes}
+	for _, planet := range d.Planets {
+		scan.Planets = append(scan.Planets, SimPlanet{
+			Name:   planet.Name,
+			Center: []float64{planet.Coordinates[0], planet.Coordinates[1], planet.Coordinates[2]},
+			Host:   planet.Host,
+			Port:   planet.Port,
+		})
+	}
+	return scan, nil
+}
+
+// dial opens an authenticated connection and swallows the greeting.
+func (p *primordiaBackend) dial() (*primordiaConn, error) {
+	conn, err := net.Dial("tcp", p.addr())
+	if err != nil {
+		return nil, fmt.Errorf("connect failed: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	if _, err := conn.Write([]byte(p.authPass + p.delimiter)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("auth failed: %w", err)
+	}
+	_, _ = readDelimited(conn, reader, p.delimiter)
+	return &primordiaConn{conn: conn, reader: reader}, nil
+}
+
+func (p *primordiaBackend) cube(name string) (*primordiaConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.conns[name]
+	if !ok {
+		return nil, fmt.Errorf("no connection")
+	}
+	return c, nil
+}
+
+// SpawnCube opens the cube's persistent connection and creates it in the
+// world. The server binds apply_force and get_cube_state to the connection
+// that spawned the cube, and construct.Cube keeps that connection private,
+// so spawning and position reads cannot go through it.
+func (p *primordiaBackend) SpawnCube(name string, pos []float64) (string, error) {
+	c, err := p.dial()
+	if err != nil {
+		return "", err
+	}
+	cmd := map[string]any{
+		"type":      "spawn_cube",
+		"cube_name": name,
+		"position":  pos,
+		"rotation":  []float64{0, 0, 0},
+		"is_base":   true,
+	}
+	if err := writeDelimited(c.conn, cmd, p.delimiter); err != nil {
+		c.conn.Close()
+		return "", fmt.Errorf("spawn failed: %w", err)
+	}
+
+	name += "_BASE" // the server suffixes base cubes
+	p.mu.Lock()
+	p.conns[name] = c
+	p.mu.Unlock()
+	return name, nil
+}
+
+// cubeList asks the server for the name of every live cube.
+func (p *primordiaBackend) cubeList(c *primordiaConn) ([]string, error) {
+	if err := writeDelimited(c.conn, map[string]any{"type": "get_cube_list"}, p.delimiter); err != nil {
+		return nil, fmt.Errorf("cube list request failed: %w", err)
+	}
+	raw, err := readDelimited(c.conn, c.reader, p.delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("cube list read failed: %w", err)
+	}
+	var list struct {
+		Cubes []string `json:"cubes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	return list.Cubes, nil
+}
+
+// eachCube runs send on every live cube, re-listing up to passes times
+// (with pause in between) to catch cubes that appear late. It stops early
+// once the list comes back empty.
+func (p *primordiaBackend) eachCube(passes int, pause time.Duration, send func(c *primordiaConn, cube string) error) error {
+	c, err := p.dial()
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+
+	for pass := 0; pass < passes; pass++ {
+		cubes, err := p.cubeList(c)
+		if err != nil {
+			return err
+		}
+		if len(cubes) == 0 {
+			return nil
+		}
+		for _, cube := range cubes {
+			if err := send(c, cube); err != nil {
+				return fmt.Errorf("%s: %w", cube, err)
+			}
+		}
+		time.Sleep(pause)
+	}
+	return nil
+}
+
+// UnfreezeAll releases every cube in the world, as construct does, but
+// reports connection failures instead of printing them.
+func (p *primordiaBackend) UnfreezeAll() error {
+	return p.eachCube(3, 200*time.Millisecond, func(c *primordiaConn, cube string) error {
+		return writeDelimited(c.conn, map[string]any{"type": "freeze_cube", "cube_name": cube, "freeze": false}, p.delimiter)
+	})
+}
+
+func (p *primordiaBackend) ApplyForce(name string, force []float64) error {
+	c, err := p.cube(name)
+	if err != nil {
+		return err
+	}
+	return writeDelimited(c.conn, map[string]any{"type": "apply_force", "force": force}, p.delimiter)
+}
+
+// ApplyTorque always fails: the Biofoundry server only takes apply_force,
+// so rotation cannot be driven on Primordia.
+func (p *primordiaBackend) ApplyTorque(name string, torque []float64) error {
+	return errPrimordiaTorque
+}
+
+// Position asks the server for the cube's current position.
+func (p *primordiaBackend) Position(name string) ([]float64, error) {
+	c, err := p.cube(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeDelimited(c.conn, map[string]any{"type": "get_cube_state"}, p.delimiter); err != nil {
+		return nil, fmt.Errorf("state request failed: %w", err)
+	}
+	raw, err := readDelimited(c.conn, c.reader, p.delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("state read failed: %w", err)
+	}
+
+	var state struct {
+		Position []float64 `json:"position"`
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	if len(state.Position) != 3 {
+		return nil, fmt.Errorf("invalid position format")
+	}
+	return state.Position, nil
+}
+
+// DespawnCube removes the cube through construct and closes its connection.
+func (p *primordiaBackend) DespawnCube(name string) error {
+	cube := &construct.Cube[float64]{Name: name, ServerAddr: p.addr(), AuthPass: p.authPass, Delimiter: p.delimiter}
+	if err := cube.Despawn(); err != nil {
+		return err
+	}
+	p.closeCube(name)
+	return nil
+}
+
+// DestroyAll despawns every cube in the world and closes every cube
+// connection, even when the server stops answering part way.
+func (p *primordiaBackend) DestroyAll() error {
+	err := p.eachCube(5, 500*time.Millisecond, func(c *primordiaConn, cube string) error {
+		return writeDelimited(c.conn, map[string]any{"type": "despawn_cube", "cube_name": cube}, p.delimiter)
+	})
+	p.mu.Lock()
+	names := make([]string, 0, len(p.conns))
+	for name := range p.conns {
+		names = append(names, name)
+	}
+	p.mu.Unlock()
+	for _, name := range names {
+		p.closeCube(name)
+	}
+	return err
+}
+
+func (p *primordiaBackend) closeCube(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.conns[name]; ok {
+		c.conn.Close()
+		delete(p.conns, name)
+	}
+}
+
+func writeDelimited(conn net.Conn, msg map[string]any, delimiter string) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, delimiter...))
+	return err
+}
+
+// readDelimited reads one reply up to the delimiter. Unlike construct's
+// helper it keeps a single reader per connection so nothing buffered is lost.
+func readDelimited(conn net.Conn, reader *bufio.Reader, delimiter string) (string, error) {
+	conn.SetReadDeadline(time.Now().Add(cubeReadTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	last := delimiter[len(delimiter)-1]
+	var b strings.Builder
+	for {
+		chunk, err := reader.ReadString(last)
+		b.WriteString(chunk)
+		if strings.HasSuffix(b.String(), delimiter) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(strings.TrimSuffix(b.String(), delimiter)), nil
+}
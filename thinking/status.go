@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -13,6 +14,9 @@ type ExperimentStatus struct {
 	Variant    int       `json:"variant"`
 	Stage      string    `json:"stage"`
 	Message    string    `json:"message"`
+	Sigma      float64   `json:"sigma,omitempty"` // set on "StepSize" updates
+
+	CurriculumStage *int `json:"curriculum_stage,omitempty"` // set once the generation's stage is known
 }
 
 type ScoreRecord struct {
@@ -26,11 +30,18 @@ type ScoreRecord struct {
 var (
 	StatusUpdates []ExperimentStatus
 	statusMu      sync.Mutex
+
+	// curriculumStages lets every update of a generation carry its stage
+	curriculumStages = map[string]int{}
 )
 
+func curriculumKey(gen int, numType, mode string) string {
+	return fmt.Sprintf("%d_%s_%s", gen, numType, mode)
+}
+
 func AppendStatus(gen int, numType, mode string, variant int, stage, msg string) {
 	statusMu.Lock()
-	StatusUpdates = append(StatusUpdates, ExperimentStatus{
+	status := ExperimentStatus{
 		Timestamp:  time.Now(),
 		Generation: gen,
 		NumType:    numType,
@@ -38,6 +49,44 @@ func AppendStatus(gen int, numType, mode string, variant int, stage, msg string)
 		Variant:    variant,
 		Stage:      stage,
 		Message:    msg,
+	}
+	if c, ok := curriculumStages[curriculumKey(gen, numType, mode)]; ok {
+		status.CurriculumStage = &c
+	}
+	StatusUpdates = append(StatusUpdates, status)
+	statusMu.Unlock()
+}
+
+// AppendCurriculumStatus logs the curriculum stage a generation evaluates
+// at; later updates for the same generation repeat it.
+func AppendCurriculumStatus(gen int, numType, mode string, stage int, msg string) {
+	statusMu.Lock()
+	curriculumStages[curriculumKey(gen, numType, mode)] = stage
+	StatusUpdates = append(StatusUpdates, ExperimentStatus{
+		Timestamp:       time.Now(),
+		Generation:      gen,
+		NumType:         numType,
+		Mode:            mode,
+		Variant:         -1,
+		Stage:           "Curriculum",
+		Message:         msg,
+		CurriculumStage: &stage,
+	})
+	statusMu.Unlock()
+}
+
+// AppendSigmaStatus logs the mutation step size a generation runs with.
+func AppendSigmaStatus(gen int, numType, mode string, sigma float64, msg string) {
+	statusMu.Lock()
+	StatusUpdates = append(StatusUpdates, ExperimentStatus{
+		Timestamp:  time.Now(),
+		Generation: gen,
+		NumType:    numType,
+		Mode:       mode,
+		Variant:    -1,
+		Stage:      "StepSize",
+		Message:    msg,
+		Sigma:      sigma,
 	})
 	statusMu.Unlock()
 }
@@ -1,11 +1,7 @@
 package main
 
 import (
-	"os"
 	"time"
-
-	"github.com/OpenFluke/discover"
-	"github.com/gofiber/websocket/v2"
 )
 
 type GameStatus struct {
@@ -29,43 +25,39 @@ func startStatusPoller() {
 		for {
 			time.Sleep(1 * time.Second)
 
-			hostName := os.Getenv("GAME_HOST")
-			if hostName == "" {
-				hostName = "localhost"
-			}
-
-			cfg := discover.Config{
-				Hosts:      []string{hostName},
-				StartPort:  14000,
-				PortStep:   3,
-				NumPods:    1,
-				AuthPass:   "my_secure_password",
-				Delimiter:  "<???DONE???---",
-				TimeoutSec: 5,
-			}
-
-			d := discover.NewDiscover(cfg)
-			d.ScanAll()
-
-			planetSummaries := make([]PlanetSummary, 0, len(d.Planets))
-			for _, p := range d.Planets {
-				planetSummaries = append(planetSummaries, PlanetSummary{
-					Name: p.Name,
-					Pos:  p.Coordinates,
-					Host: p.Host,
-					Port: p.Port,
-				})
-			}
-
+			// every pod shares the planet layout; cubes are spread across them
+			var planetSummaries []PlanetSummary
+			seen := make(map[string]bool)
 			hostCount := make(map[string]int)
-			for _, host := range d.Cubes {
-				hostCount[host]++
+			totalCubes, reachable := 0, 0
+			for _, sim := range currentSims() {
+				scan, err := sim.ScanPlanets()
+				if err != nil {
+					continue
+				}
+				reachable++
+				for _, p := range scan.Planets {
+					if seen[p.Name] {
+						continue
+					}
+					seen[p.Name] = true
+					summary := PlanetSummary{Name: p.Name, Host: p.Host, Port: p.Port}
+					copy(summary.Pos[:], p.Center)
+					planetSummaries = append(planetSummaries, summary)
+				}
+				for _, host := range scan.Cubes {
+					hostCount[host]++
+				}
+				totalCubes += len(scan.Cubes)
+			}
+			if reachable == 0 {
+				continue
 			}
 
 			status := GameStatus{
 				Timestamp:    time.Now().Format(time.RFC3339),
-				TotalCubes:   len(d.Cubes),
-				TotalPlanets: len(d.Planets),
+				TotalCubes:   totalCubes,
+				TotalPlanets: len(planetSummaries),
 				Planets:      planetSummaries,
 				CubeHosts:    hostCount,
 			}
@@ -79,10 +71,5 @@ func startStatusPoller() {
 }
 
 func broadcastStatus(msg []byte) {
-	for conn := range wsClients {
-		if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-			conn.Close()
-			delete(wsClients, conn)
-		}
-	}
+	broadcast(msg)
 }
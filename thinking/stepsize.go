@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Step-size adaptation modes selectable with step_size.adaptation
+const (
+	StepSizeFixed        = "none"
+	StepSizeOneFifth     = "one_fifth"
+	StepSizeSelfAdaptive = "self_adaptive"
+)
+
+const (
+	defaultOneFifthDamping = 3.0
+	defaultSelfAdaptTau    = 0.2
+)
+
+func (s StepSizeConfig) AdaptationName() string {
+	if s.Adaptation == "" {
+		return StepSizeFixed
+	}
+	return strings.ToLower(s.Adaptation)
+}
+
+// clampSigma keeps sigma inside [min_sigma, max_sigma] when they are set.
+func (s StepSizeConfig) clampSigma(sigma float64) float64 {
+	if s.MinSigma > 0 && sigma < s.MinSigma {
+		sigma = s.MinSigma
+	}
+	if s.MaxSigma > 0 && sigma > s.MaxSigma {
+		sigma = s.MaxSigma
+	}
+	return sigma
+}
+
+// StepSizeState carries the one-fifth rule between generations.
+type StepSizeState struct {
+	Generation int     `json:"generation"`
+	NumType    string  `json:"num_type"`
+	Mode       string  `json:"mode"`
+	Sigma      float64 `json:"sigma"`
+	BestSoFar  float64 `json:"best_so_far"` // best score before this generation
+	HasBest    bool    `json:"has_best"`
+	Improved   bool    `json:"improved"` // whether the previous generation beat BestSoFar
+}
+
+func stepSizePath(gen int, numType, mode string) string {
+	return filepath.Join("models", strconv.Itoa(gen), "step_size", fmt.Sprintf("%s_%s.json", numType, mode))
+}
+
+func loadStepSizeState(gen int, numType, mode string) (*StepSizeState, error) {
+	data, err := os.ReadFile(stepSizePath(gen, numType, mode))
+	if err != nil {
+		return nil, err
+	}
+	var s StepSizeState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func saveStepSizeState(s *StepSizeState) error {
+	path := stepSizePath(s.Generation, s.NumType, s.Mode)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// oneFifthSigma applies the 1/5th success rule once per generation: sigma
+// grows when the previous generation improved on the best score so far and
+// shrinks otherwise, balancing out at one success in five. A previous
+// generation without state (one_fifth switched on mid-run) starts from
+// spectrum_max_stddev, and one without ranked results leaves sigma as it was,
+// so a generation always gets a step size.
+func oneFifthSigma(cfg *ExperimentConfig, gen int, numType, mode string) float64 {
+	if s, err := loadStepSizeState(gen, numType, mode); err == nil {
+		return s.Sigma
+	}
+
+	initial := cfg.StepSize.clampSigma(cfg.SpectrumMaxStdDev)
+	state := &StepSizeState{Generation: gen, NumType: numType, Mode: mode, Sigma: initial}
+	if gen > 0 {
+		prev, err := loadStepSizeState(gen-1, numType, mode)
+		if err != nil {
+			fmt.Printf("⚠️ No step size state for Gen %d %s_%s (%v) — starting from σ=%.6f\n", gen-1, numType, mode, err, initial)
+			prev = &StepSizeState{Sigma: initial}
+		}
+		state.Sigma = cfg.StepSize.clampSigma(prev.Sigma)
+		state.BestSoFar, state.HasBest = prev.BestSoFar, prev.HasBest
+
+		ranked, err := loadRankedResults(gen-1, numType, mode)
+		switch {
+		case err != nil:
+			fmt.Printf("⚠️ No ranked results for Gen %d %s_%s (%v) — keeping σ=%.6f\n", gen-1, numType, mode, err, state.Sigma)
+		case len(ranked) == 0:
+			fmt.Printf("⚠️ Gen %d %s_%s has no ranked results — keeping σ=%.6f\n", gen-1, numType, mode, state.Sigma)
+		default:
+			best := fittestResult(ranked).MeanProgress
+			success := 0.0
+			if !prev.HasBest || best > prev.BestSoFar {
+				success = 1
+			}
+			damping := cfg.StepSize.Damping
+			if damping <= 0 {
+				damping = defaultOneFifthDamping
+			}
+
+			state.Sigma = cfg.StepSize.clampSigma(prev.Sigma * math.Exp((success-0.2)/(0.8*damping)))
+			state.Improved = success == 1
+			if state.Improved {
+				state.BestSoFar, state.HasBest = best, true
+			}
+		}
+	}
+	if err := saveStepSizeState(state); err != nil {
+		fmt.Printf("⚠️ Could not save step size state: %v\n", err)
+	}
+	return state.Sigma
+}
+
+// selfAdaptSigma mutates a parent's sigma log-normally for one child. The
+// draw uses its own stream so it does not shift the weight noise.
+func selfAdaptSigma(cfg StepSizeConfig, parent float64, seed int64) float64 {
+	tau := cfg.Tau
+	if tau <= 0 {
+		tau = defaultSelfAdaptTau
+	}
+	rng := rand.New(rand.NewSource(^seed))
+	return cfg.clampSigma(parent * math.Exp(tau*rng.NormFloat64()))
+}
+
+// inheritedSigma is the sigma a variant was created with, or the configured
+// spectrum_max_stddev for variants that carry none (base model, champion).
+func inheritedSigma(cfg *ExperimentConfig, modelPath string, gen int, numType, mode string) float64 {
+	m, err := LoadManifest(gen)
+	if err == nil {
+		for _, rec := range m.Variants {
+			if rec.NumType == numType && rec.Mode == mode && variantPath(gen, numType, mode, rec.Variant) == modelPath && rec.StdDev > 0 {
+				return rec.StdDev
+			}
+		}
+	}
+	return cfg.StepSize.clampSigma(cfg.SpectrumMaxStdDev)
+}
+
+// generationSigma is the step size a mutation-based generation starts from:
+// fixed, the one-fifth rule's current value, or (self-adaptive) the sigma
+// its best parent carried.
+func (e *Experiment[T, M]) generationSigma(parentPath string) (float64, error) {
+	switch e.Config.StepSize.AdaptationName() {
+	case StepSizeOneFifth:
+		return oneFifthSigma(e.Config, e.Gen, e.NumType, e.Mode.String()), nil
+	case StepSizeSelfAdaptive:
+		if e.Gen == 0 {
+			return e.Config.StepSize.clampSigma(e.Config.SpectrumMaxStdDev), nil
+		}
+		return inheritedSigma(e.Config, parentPath, e.Gen-1, e.NumType, e.Mode.String()), nil
+	case StepSizeFixed:
+		return e.Config.SpectrumMaxStdDev, nil
+	default:
+		return 0, fmt.Errorf("unknown step_size.adaptation %q", e.Config.StepSize.Adaptation)
+	}
+}
+
+// childSigma is the stddev one child is mutated with.
+func (e *Experiment[T, M]) childSigma(parentSigma float64, seed int64) float64 {
+	if e.Config.StepSize.AdaptationName() == StepSizeSelfAdaptive {
+		return selfAdaptSigma(e.Config.StepSize, parentSigma, seed)
+	}
+	return parentSigma
+}
+
+// reportSigma logs the generation's step size to the status stream and the
+// generation manifest; source says what set it.
+func (e *Experiment[T, M]) reportSigma(sigma float64, source string) {
+	mode := e.Mode.String()
+	AppendSigmaStatus(e.Gen, e.NumType, mode, sigma, fmt.Sprintf("σ=%.6f (%s)", sigma, source))
+	if err := RecordSigma(e.Gen, e.NumType, mode, sigma); err != nil {
+		fmt.Printf("⚠️ Could not record sigma in manifest: %v\n", err)
+	}
+}
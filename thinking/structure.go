@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+// Structural mutation operators selectable with
+// mutation_strategy.structural.operators. The input and output widths are
+// fixed by the observations and actions, so only hidden layers grow,
+// shrink or change activation.
+const (
+	StructAddNeuron          = "add_neuron"
+	StructRemoveNeuron       = "remove_neuron"
+	StructAddLayer           = "add_layer"
+	StructRemoveLayer        = "remove_layer"
+	StructChangeActivation   = "change_activation"
+	StructToggleConnectivity = "toggle_connectivity"
+)
+
+var allStructuralOps = []string{
+	StructAddNeuron, StructRemoveNeuron, StructAddLayer,
+	StructRemoveLayer, StructChangeActivation, StructToggleConnectivity,
+}
+
+var defaultActivations = []string{"relu", "sigmoid", "tanh", "leaky_relu", "elu", "linear"}
+
+// localWindow matches the 5×5, stride-1 window paragon wires non-full
+// layers with.
+const localWindow = 5
+
+func (s StructuralMutation) OperatorNames() []string {
+	if len(s.Operators) == 0 {
+		return allStructuralOps
+	}
+	return s.Operators
+}
+
+func (s StructuralMutation) ActivationNames() []string {
+	if len(s.Activations) == 0 {
+		return defaultActivations
+	}
+	return s.Activations
+}
+
+// architectureOf summarises a network's topology for the manifest, e.g.
+// "3x1 linear | 128x1 relu | 64x1 tanh local | 3x1 tanh".
+func architectureOf[T paragon.Numeric](net *paragon.Network[T]) string {
+	parts := make([]string, len(net.Layers))
+	for l, layer := range net.Layers {
+		parts[l] = fmt.Sprintf("%dx%d %s", layer.Width, layer.Height, layer.Neurons[0][0].Activation)
+		if l > 0 && !isFullyConnected(net, l) {
+			parts[l] += " local"
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func sameArchitecture[T paragon.Numeric](a, b *paragon.Network[T]) bool {
+	return architectureOf(a) == architectureOf(b)
+}
+
+// isFullyConnected reports whether every neuron of layer l reads every
+// neuron of layer l-1.
+func isFullyConnected[T paragon.Numeric](net *paragon.Network[T], l int) bool {
+	prev := net.Layers[l-1].Width * net.Layers[l-1].Height
+	for _, row := range net.Layers[l].Neurons {
+		for _, n := range row {
+			if len(n.Inputs) != prev {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// initialWeight draws one weight from paragon's initial range for a neuron
+// with fanIn inputs: (-1, 1) for floats, ±typeMax/√fan-in for integers
+// (0…scale when unsigned).
+func initialWeight[T paragon.Numeric](rng *rand.Rand, fanIn int) T {
+	lo, hi, integer := numericRange[T]()
+	scale := 1.0
+	if integer && fanIn > 0 {
+		scale = math.Max(1, hi/math.Floor(math.Sqrt(float64(fanIn))))
+	}
+	if integer && lo == 0 {
+		return toNumeric[T](rng.Float64() * scale)
+	}
+	return toNumeric[T]((rng.Float64()*2 - 1) * scale)
+}
+
+// wireLayer rebuilds layer l's inputs from layer l-1, fully or through the
+// local window. Connections that already exist keep their weight; new ones
+// get fresh(fanIn), or zero when fresh is nil so the function is unchanged.
+func wireLayer[T paragon.Numeric](net *paragon.Network[T], l int, full bool, fresh func(fanIn int) T) {
+	prev := net.Layers[l-1]
+	for y, row := range net.Layers[l].Neurons {
+		for x, n := range row {
+			old := map[[2]int]T{}
+			for _, c := range n.Inputs {
+				if c.SourceLayer == l-1 {
+					old[[2]int{c.SourceX, c.SourceY}] = c.Weight
+				}
+			}
+
+			var sources [][2]int
+			if full {
+				for sy := 0; sy < prev.Height; sy++ {
+					for sx := 0; sx < prev.Width; sx++ {
+						sources = append(sources, [2]int{sx, sy})
+					}
+				}
+			} else {
+				half := localWindow / 2
+				for dy := -half; dy <= half; dy++ {
+					for dx := -half; dx <= half; dx++ {
+						sx, sy := x+dx, y+dy
+						if sx >= 0 && sx < prev.Width && sy >= 0 && sy < prev.Height {
+							sources = append(sources, [2]int{sx, sy})
+						}
+					}
+				}
+			}
+
+			inputs := make([]paragon.Connection[T], len(sources))
+			for k, s := range sources {
+				w, ok := old[s]
+				if !ok && fresh != nil {
+					w = fresh(len(sources))
+				}
+				inputs[k] = paragon.Connection[T]{SourceLayer: l - 1, SourceX: s[0], SourceY: s[1], Weight: w}
+			}
+			n.Inputs = inputs
+		}
+	}
+}
+
+// mutateStructure applies one randomly chosen structural operator with
+// probability rate and returns its description, or "" when none applied.
+// Operators that cannot apply (e.g. removing the only hidden layer) are
+// skipped in favour of another.
+func mutateStructure[T paragon.Numeric](net *paragon.Network[T], s StructuralMutation, rng *rand.Rand) (string, error) {
+	if s.Rate <= 0 || rng.Float64() >= s.Rate {
+		return "", nil
+	}
+	ops := s.OperatorNames()
+	for _, i := range rng.Perm(len(ops)) {
+		desc, err := applyStructuralOp(net, s, strings.ToLower(ops[i]), rng)
+		if err != nil || desc != "" {
+			return desc, err
+		}
+	}
+	return "", nil
+}
+
+func applyStructuralOp[T paragon.Numeric](net *paragon.Network[T], s StructuralMutation, op string, rng *rand.Rand) (string, error) {
+	hidden := len(net.Layers) - 2
+	fresh := func(fanIn int) T { return initialWeight[T](rng, fanIn) }
+
+	switch op {
+	case StructAddNeuron:
+		var candidates []int
+		for l := 1; l <= hidden; l++ {
+			if s.MaxWidth <= 0 || net.Layers[l].Width < s.MaxWidth {
+				candidates = append(candidates, l)
+			}
+		}
+		if len(candidates) == 0 {
+			return "", nil
+		}
+		l := candidates[rng.Intn(len(candidates))]
+		full, nextFull := isFullyConnected(net, l), isFullyConnected(net, l+1)
+		layer := &net.Layers[l]
+		for y := range layer.Neurons {
+			layer.Neurons[y] = append(layer.Neurons[y], &paragon.Neuron[T]{
+				Activation: layer.Neurons[y][0].Activation,
+				Type:       "dense",
+				IsNew:      true,
+			})
+		}
+		layer.Width++
+		wireLayer(net, l, full, fresh)
+		// the new neuron's outgoing weights start at zero
+		wireLayer(net, l+1, nextFull, nil)
+		return fmt.Sprintf("%s layer %d → width %d", op, l, layer.Width), nil
+
+	case StructRemoveNeuron:
+		var candidates []int
+		for l := 1; l <= hidden; l++ {
+			if net.Layers[l].Width > 1 {
+				candidates = append(candidates, l)
+			}
+		}
+		if len(candidates) == 0 {
+			return "", nil
+		}
+		l := candidates[rng.Intn(len(candidates))]
+		layer := &net.Layers[l]
+		col := rng.Intn(layer.Width)
+		for y := range layer.Neurons {
+			layer.Neurons[y] = append(layer.Neurons[y][:col], layer.Neurons[y][col+1:]...)
+		}
+		layer.Width--
+		for _, row := range net.Layers[l+1].Neurons {
+			for _, n := range row {
+				kept := n.Inputs[:0]
+				for _, c := range n.Inputs {
+					if c.SourceX == col {
+						continue
+					}
+					if c.SourceX > col {
+						c.SourceX--
+					}
+					kept = append(kept, c)
+				}
+				n.Inputs = kept
+			}
+		}
+		return fmt.Sprintf("%s layer %d column %d → width %d", op, l, col, layer.Width), nil
+
+	case StructAddLayer:
+		if s.MaxHiddenLayers > 0 && hidden >= s.MaxHiddenLayers {
+			return "", nil
+		}
+		// the new layer copies its input layer's shape and starts as a
+		// linear identity, so the network computes exactly what it did
+		after := rng.Intn(len(net.Layers) - 1)
+		src := net.Layers[after]
+		grid := paragon.Grid[T]{Width: src.Width, Height: src.Height, Neurons: make([][]*paragon.Neuron[T], src.Height)}
+		for y := 0; y < src.Height; y++ {
+			grid.Neurons[y] = make([]*paragon.Neuron[T], src.Width)
+			for x := 0; x < src.Width; x++ {
+				n := &paragon.Neuron[T]{Activation: "linear", Type: "dense", IsNew: true}
+				for sy := 0; sy < src.Height; sy++ {
+					for sx := 0; sx < src.Width; sx++ {
+						var w T
+						if sx == x && sy == y {
+							w = 1
+						}
+						n.Inputs = append(n.Inputs, paragon.Connection[T]{SourceLayer: after, SourceX: sx, SourceY: sy, Weight: w})
+					}
+				}
+				grid.Neurons[y][x] = n
+			}
+		}
+		net.Layers = append(net.Layers[:after+1], append([]paragon.Grid[T]{grid}, net.Layers[after+1:]...)...)
+		shiftSources(net, after+2, 1)
+		net.OutputLayer = len(net.Layers) - 1
+		return fmt.Sprintf("%s identity %dx%d after layer %d", op, grid.Width, grid.Height, after), nil
+
+	case StructRemoveLayer:
+		if hidden < 1 {
+			return "", nil
+		}
+		l := 1 + rng.Intn(hidden)
+		prev, gone := net.Layers[l-1], net.Layers[l]
+		if prev.Width == gone.Width && prev.Height == gone.Height {
+			// same shape: the next layer keeps its weights, now reading l-1
+			for _, row := range net.Layers[l+1].Neurons {
+				for _, n := range row {
+					for k := range n.Inputs {
+						n.Inputs[k].SourceLayer = l - 1
+					}
+				}
+			}
+		} else {
+			full := isFullyConnected(net, l+1)
+			for _, row := range net.Layers[l+1].Neurons {
+				for _, n := range row {
+					n.Inputs = nil
+				}
+			}
+			net.Layers = append(net.Layers[:l], net.Layers[l+1:]...)
+			wireLayer(net, l, full, fresh)
+			shiftSources(net, l+1, -1)
+			net.OutputLayer = len(net.Layers) - 1
+			return fmt.Sprintf("%s layer %d (rewired)", op, l), nil
+		}
+		net.Layers = append(net.Layers[:l], net.Layers[l+1:]...)
+		shiftSources(net, l+1, -1)
+		net.OutputLayer = len(net.Layers) - 1
+		return fmt.Sprintf("%s layer %d", op, l), nil
+
+	case StructChangeActivation:
+		if hidden < 1 {
+			return "", nil
+		}
+		l := 1 + rng.Intn(hidden)
+		current := net.Layers[l].Neurons[0][0].Activation
+		var choices []string
+		for _, a := range s.ActivationNames() {
+			if a != current {
+				choices = append(choices, a)
+			}
+		}
+		if len(choices) == 0 {
+			return "", nil
+		}
+		act := choices[rng.Intn(len(choices))]
+		for _, row := range net.Layers[l].Neurons {
+			for _, n := range row {
+				n.Activation = act
+			}
+		}
+		return fmt.Sprintf("%s layer %d %s → %s", op, l, current, act), nil
+
+	case StructToggleConnectivity:
+		l := 1 + rng.Intn(len(net.Layers)-1)
+		full := isFullyConnected(net, l)
+		// local → full adds zero weights (same function); full → local
+		// keeps the weights inside each neuron's window
+		wireLayer(net, l, !full, nil)
+		to := "full"
+		if full {
+			to = "local"
+		}
+		return fmt.Sprintf("%s layer %d → %s", op, l, to), nil
+
+	default:
+		return "", fmt.Errorf("unknown structural operator %q", op)
+	}
+}
+
+// shiftSources moves every connection of layers from..end by delta layers,
+// after a layer was inserted (+1) or removed (-1) in front of them.
+func shiftSources[T paragon.Numeric](net *paragon.Network[T], from, delta int) {
+	for l := from; l < len(net.Layers); l++ {
+		for _, row := range net.Layers[l].Neurons {
+			for _, n := range row {
+				for k := range n.Inputs {
+					n.Inputs[k].SourceLayer += delta
+				}
+			}
+		}
+	}
+}
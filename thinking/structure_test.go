@@ -0,0 +1,138 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	paragon "github.com/OpenFluke/PARAGON"
+)
+
+var structTestInput = [][]float64{{0.3, -0.7, 0.1, 0.9, -0.2, 0.5, -0.4, 0.8}}
+
+// newStructTestNet is 8x1 linear | 8x1 relu | 6x1 tanh | 3x1 tanh, with
+// the hidden and output layers fully or locally connected.
+func newStructTestNet(t *testing.T, full bool) *paragon.Network[float64] {
+	t.Helper()
+	return paragon.NewNetwork[float64](
+		[]struct{ Width, Height int }{{8, 1}, {8, 1}, {6, 1}, {3, 1}},
+		[]string{"linear", "relu", "tanh", "tanh"},
+		[]bool{true, full, full, full},
+	)
+}
+
+func forwardOf(net *paragon.Network[float64]) []float64 {
+	net.Forward(structTestInput)
+	return append([]float64(nil), net.GetOutput()...)
+}
+
+// checkConnections fails unless every input of every neuron reads a neuron
+// that exists in an earlier layer.
+func checkConnections(t *testing.T, net *paragon.Network[float64], label string) {
+	t.Helper()
+	if net.OutputLayer != len(net.Layers)-1 {
+		t.Fatalf("%s: OutputLayer %d, have %d layers", label, net.OutputLayer, len(net.Layers))
+	}
+	for l := 1; l < len(net.Layers); l++ {
+		layer := net.Layers[l]
+		if len(layer.Neurons) != layer.Height {
+			t.Fatalf("%s: layer %d has %d rows, Height %d", label, l, len(layer.Neurons), layer.Height)
+		}
+		for y, row := range layer.Neurons {
+			if len(row) != layer.Width {
+				t.Fatalf("%s: layer %d row %d has %d neurons, Width %d", label, l, y, len(row), layer.Width)
+			}
+			for x, n := range row {
+				for _, c := range n.Inputs {
+					if c.SourceLayer < 0 || c.SourceLayer >= l {
+						t.Fatalf("%s: layer %d (%d,%d) reads layer %d", label, l, x, y, c.SourceLayer)
+					}
+					src := net.Layers[c.SourceLayer]
+					if c.SourceX < 0 || c.SourceX >= src.Width || c.SourceY < 0 || c.SourceY >= src.Height {
+						t.Fatalf("%s: layer %d (%d,%d) reads (%d,%d) of %dx%d layer %d",
+							label, l, x, y, c.SourceX, c.SourceY, src.Width, src.Height, c.SourceLayer)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestStructuralOpsPreserveFunction(t *testing.T) {
+	tests := []struct {
+		op     string
+		full   bool
+		suffix string // only descriptions ending in this must keep the function
+	}{
+		{StructAddLayer, true, ""},
+		{StructAddLayer, false, ""},
+		{StructToggleConnectivity, false, "→ full"},
+	}
+	for _, tt := range tests {
+		base := newStructTestNet(t, tt.full)
+		want := forwardOf(base)
+		for seed := int64(0); seed < 20; seed++ {
+			net, err := cloneNetwork(base)
+			if err != nil {
+				t.Fatalf("clone: %v", err)
+			}
+			desc, err := applyStructuralOp(net, StructuralMutation{}, tt.op, rand.New(rand.NewSource(seed)))
+			if err != nil || desc == "" {
+				t.Fatalf("%s seed %d: desc %q, err %v", tt.op, seed, desc, err)
+			}
+			if !strings.HasSuffix(desc, tt.suffix) {
+				t.Fatalf("%s seed %d: %q, want suffix %q", tt.op, seed, desc, tt.suffix)
+			}
+			checkConnections(t, net, desc)
+			got := forwardOf(net)
+			for k := range want {
+				if math.Abs(got[k]-want[k]) > 1e-9 {
+					t.Fatalf("%s: output %v, want %v", desc, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestStructuralRemoveKeepsConnectionsValid(t *testing.T) {
+	tests := []struct {
+		op   string
+		full bool
+	}{
+		{StructRemoveNeuron, true},
+		{StructRemoveNeuron, false},
+		{StructRemoveLayer, true},
+		{StructRemoveLayer, false},
+	}
+	for _, tt := range tests {
+		for seed := int64(0); seed < 20; seed++ {
+			net := newStructTestNet(t, tt.full)
+			rng := rand.New(rand.NewSource(seed))
+			// apply twice so a removal also runs on an already-reshaped net
+			for step := 0; step < 2; step++ {
+				desc, err := applyStructuralOp(net, StructuralMutation{}, tt.op, rng)
+				if err != nil {
+					t.Fatalf("%s seed %d: %v", tt.op, seed, err)
+				}
+				if desc == "" {
+					break
+				}
+				checkConnections(t, net, desc)
+				want := forwardOf(net)
+
+				clone, err := cloneNetwork(net)
+				if err != nil {
+					t.Fatalf("%s: ToS/FromS round-trip: %v", desc, err)
+				}
+				checkConnections(t, clone, desc+" after round-trip")
+				got := forwardOf(clone)
+				for k := range want {
+					if got[k] != want[k] {
+						t.Fatalf("%s: round-trip output %v, want %v", desc, got, want)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// defaultTrajectoryRate is used when trajectory.sample_rate_hz is unset.
+const defaultTrajectoryRate = 5.0
+
+// AgentTrajectory is stored column-wise to keep the per-variant file small.
+type AgentTrajectory struct {
+	Time     []float64   `json:"t"`
+	Position [][]float64 `json:"pos"`
+	Output   [][]float64 `json:"out"`
+}
+
+type VariantTrajectory struct {
+	Generation   int                         `json:"generation"`
+	NumType      string                      `json:"num_type"`
+	Mode         string                      `json:"mode"`
+	Variant      int                         `json:"variant"`
+	SampleRateHz float64                     `json:"sample_rate_hz"`
+	Agents       map[string]*AgentTrajectory `json:"agents"`
+}
+
+// trajectoryRecorder samples every cube at most SampleRateHz times a second.
+// It is driven from the pulse loop's onTick, so positions are never read
+// while a pulse is updating them.
+type trajectoryRecorder struct {
+	interval time.Duration
+	next     time.Duration
+	data     VariantTrajectory
+}
+
+func newTrajectoryRecorder(cfg TrajectoryConfig, gen int, numType, mode string, variant int) *trajectoryRecorder {
+	rate := cfg.SampleRateHz
+	if rate <= 0 {
+		rate = defaultTrajectoryRate
+	}
+	return &trajectoryRecorder{
+		interval: time.Duration(float64(time.Second) / rate),
+		data: VariantTrajectory{
+			Generation:   gen,
+			NumType:      numType,
+			Mode:         mode,
+			Variant:      variant,
+			SampleRateHz: rate,
+			Agents:       make(map[string]*AgentTrajectory),
+		},
+	}
+}
+
+// Due reports whether a sample should be taken at elapsed and, if so,
+// schedules the next one.
+func (r *trajectoryRecorder) Due(elapsed time.Duration) bool {
+	if elapsed < r.next {
+		return false
+	}
+	for r.next <= elapsed {
+		r.next += r.interval
+	}
+	return true
+}
+
+// Record appends one sample for a cube.
+func (r *trajectoryRecorder) Record(name string, elapsed time.Duration, pos, output []float64) {
+	tr, ok := r.data.Agents[name]
+	if !ok {
+		tr = &AgentTrajectory{}
+		r.data.Agents[name] = tr
+	}
+	tr.Time = append(tr.Time, roundTo(elapsed.Seconds(), 3))
+	tr.Position = append(tr.Position, roundSlice(pos, 3))
+	tr.Output = append(tr.Output, roundSlice(output, 4))
+}
+
+// Marshal returns the compact (unindented) JSON for the trajectory file.
+func (r *trajectoryRecorder) Marshal() ([]byte, error) {
+	return json.Marshal(r.data)
+}
+
+func roundTo(v float64, places int) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+func roundSlice(vals []float64, places int) []float64 {
+	out := make([]float64, len(vals))
+	for i, v := range vals {
+		out[i] = roundTo(v, places)
+	}
+	return out
+}
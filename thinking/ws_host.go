@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,8 +16,29 @@ import (
 )
 
 var wsClients = make(map[*websocket.Conn]bool)
+var wsClientsMu sync.Mutex // guards wsClients; the HTTP handlers, the broadcasters and shutdown all touch it
+var wsWriteMu sync.Mutex
 var experimentConfig *ExperimentConfig // populated in engine.go
 
+// wsSend serialises writes so control replies never interleave with broadcasts
+func wsSend(conn *websocket.Conn, data []byte) error {
+	wsWriteMu.Lock()
+	defer wsWriteMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// broadcast sends data to every connected client, dropping any that fail.
+func broadcast(data []byte) {
+	wsClientsMu.Lock()
+	defer wsClientsMu.Unlock()
+	for client := range wsClients {
+		if err := wsSend(client, data); err != nil {
+			client.Close()
+			delete(wsClients, client)
+		}
+	}
+}
+
 func startWebSocketServer() {
 	app := fiber.New()
 
@@ -29,9 +51,13 @@ func startWebSocketServer() {
 	})
 
 	app.Get("/ws/status", websocket.New(func(c *websocket.Conn) {
+		wsClientsMu.Lock()
 		wsClients[c] = true
+		wsClientsMu.Unlock()
 		defer func() {
+			wsClientsMu.Lock()
 			delete(wsClients, c)
+			wsClientsMu.Unlock()
 			c.Close()
 		}()
 
@@ -39,7 +65,7 @@ func startWebSocketServer() {
 		if experimentConfig != nil {
 			configJSON := SerializeTyped(TypeExperimentConf, experimentConfig)
 			if configJSON != nil {
-				if err := c.WriteMessage(websocket.TextMessage, configJSON); err != nil {
+				if err := wsSend(c, configJSON); err != nil {
 					log.Println("❌ Failed to send config:", err)
 					return
 				}
@@ -54,7 +80,7 @@ func startWebSocketServer() {
 
 		statusJSON := SerializeTyped(TypeExperimentRunning, fullStatus)
 		if statusJSON != nil {
-			if err := c.WriteMessage(websocket.TextMessage, statusJSON); err != nil {
+			if err := wsSend(c, statusJSON); err != nil {
 				log.Println("❌ Failed to send full status on connect:", err)
 				return
 			}
@@ -64,12 +90,32 @@ func startWebSocketServer() {
 		scoreRecords := collectAllScores()
 		scoreJSON := SerializeTyped(TypeScoresOverview, scoreRecords)
 		if scoreJSON != nil {
-			if err := c.WriteMessage(websocket.TextMessage, scoreJSON); err != nil {
+			if err := wsSend(c, scoreJSON); err != nil {
 				log.Println("❌ Failed to send scoring data:", err)
 				return
 			}
 		}
 
+		// 🗺️ Behavior archives alongside the scores, when diversity is on
+		if archives := LoadAllArchives(); len(archives) > 0 {
+			if archiveJSON := SerializeTyped(TypeArchiveOverview, archives); archiveJSON != nil {
+				if err := wsSend(c, archiveJSON); err != nil {
+					log.Println("❌ Failed to send archive data:", err)
+					return
+				}
+			}
+		}
+
+		// 🧪 Train-vs-test curves, when test_planets are configured
+		if curves := LoadAllGeneralizationCurves(); len(curves) > 0 {
+			if curveJSON := SerializeTyped(TypeGeneralizationCurve, curves); curveJSON != nil {
+				if err := wsSend(c, curveJSON); err != nil {
+					log.Println("❌ Failed to send generalization curves:", err)
+					return
+				}
+			}
+		}
+
 		// ✅ Listen for incoming control messages
 		for {
 			_, msg, err := c.ReadMessage()
@@ -85,14 +131,19 @@ func startWebSocketServer() {
 			}
 
 			switch incoming.Type {
-			case "experiment_control":
+			case TypeExperimentControl:
 				var ctrl struct {
 					Action  string      `json:"action"`
 					Payload interface{} `json:"payload"`
 				}
 				if err := mapToStruct(incoming.Data, &ctrl); err == nil {
 					log.Printf("🧪 Received control: %s — %+v\n", ctrl.Action, ctrl.Payload)
-					// TODO: implement run/stop/save/load logic using ctrl.Action and ctrl.Payload
+					ack := controller.HandleControl(ctrl.Action)
+					if data := SerializeTyped(TypeControlAck, ack); data != nil {
+						if err := wsSend(c, data); err != nil {
+							log.Println("❌ Failed to send control ack:", err)
+						}
+					}
 				} else {
 					log.Println("❌ Failed to map control data")
 				}
@@ -119,35 +170,32 @@ func mapToStruct(data interface{}, out interface{}) error {
 
 func startStatusBroadcastLoop() {
 	go func() {
-		var lastSeen int
-
 		for {
 			time.Sleep(2 * time.Second)
-
-			statusMu.Lock()
-			if lastSeen >= len(StatusUpdates) {
-				statusMu.Unlock()
-				continue
-			}
-			newStatuses := StatusUpdates[lastSeen:]
-			lastSeen = len(StatusUpdates)
-			statusMu.Unlock()
-
-			if len(newStatuses) > 0 {
-				data := SerializeTyped(TypeExperimentRunning, newStatuses)
-				if data != nil {
-					for client := range wsClients {
-						if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
-							client.Close()
-							delete(wsClients, client)
-						}
-					}
-				}
-			}
+			flushStatusUpdates()
 		}
 	}()
 }
 
+var statusBroadcastSeen int
+
+// flushStatusUpdates pushes any statuses not yet broadcast to every client.
+// It also runs once on shutdown so the final entries are not lost.
+func flushStatusUpdates() {
+	statusMu.Lock()
+	if statusBroadcastSeen >= len(StatusUpdates) {
+		statusMu.Unlock()
+		return
+	}
+	newStatuses := append([]ExperimentStatus(nil), StatusUpdates[statusBroadcastSeen:]...)
+	statusBroadcastSeen = len(StatusUpdates)
+	statusMu.Unlock()
+
+	if data := SerializeTyped(TypeExperimentRunning, newStatuses); data != nil {
+		broadcast(data)
+	}
+}
+
 func collectAllScores() []ScoreRecord {
 	var records []ScoreRecord
 